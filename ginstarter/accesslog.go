@@ -0,0 +1,156 @@
+package ginstarter
+
+import (
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/acexy/golang-toolkit/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+/**
+访问日志
+在所有中间件之前执行 记录最终的响应状态码及响应大小
+慢请求及5xx响应不受采样率影响 并分别提升至Warn及Error级别
+*/
+
+type AccessLogFormat int
+
+const (
+	// AccessLogFields 以logrus字段输出 配合JSON Formatter输出结构化日志
+	AccessLogFields AccessLogFormat = iota
+	// AccessLogText 以单行文本输出
+	AccessLogText
+)
+
+// AccessLogConfig 访问日志配置
+type AccessLogConfig struct {
+	// 输出格式 默认AccessLogFields
+	Format AccessLogFormat
+	// 日志级别 默认Info
+	Level logrus.Level
+	// 采样率 (0,1) 之间时按比例记录 其他值全部记录
+	SampleRate float64
+	// 不记录的请求路径 以*结尾时按前缀匹配 如 /ping /static/*
+	ExcludePaths []string
+	// 慢请求阈值 超过阈值的请求以Warn级别记录
+	SlowThreshold time.Duration
+	// 附加的日志字段
+	Fields func(request *Request) map[string]any
+}
+
+// 访问日志中间件
+func accessLogHandler(config *AccessLogConfig) gin.HandlerFunc {
+	level := config.Level
+	if level == 0 {
+		level = logrus.InfoLevel
+	}
+	return func(ctx *gin.Context) {
+		requestPath := ctx.Request.URL.Path
+		if accessLogExcluded(config.ExcludePaths, requestPath) {
+			ctx.Next()
+			return
+		}
+		start := time.Now()
+		writer := ctx.Writer
+		ctx.Next()
+		latency := time.Since(start)
+		httpStatus := writer.Status()
+		// 被BadHttpCodeResolver包裹的响应 记录原始状态码
+		status := httpStatus
+		if resolved, ok := ctx.Get(ginCtxKeyResolvedStatus); ok {
+			status = resolved.(int)
+		}
+
+		currentLevel := level
+		slow := config.SlowThreshold > 0 && latency >= config.SlowThreshold
+		if status >= http.StatusInternalServerError {
+			currentLevel = min(currentLevel, logrus.ErrorLevel)
+		} else if slow {
+			currentLevel = min(currentLevel, logrus.WarnLevel)
+		} else if config.SampleRate > 0 && config.SampleRate < 1 && rand.Float64() >= config.SampleRate {
+			return
+		}
+		if !logger.Logrus().IsLevelEnabled(currentLevel) {
+			return
+		}
+
+		fields := logrus.Fields{
+			"method":     ctx.Request.Method,
+			"route":      ctx.FullPath(),
+			"path":       requestPath,
+			"status":     status,
+			"latency_ms": float64(latency.Microseconds()) / 1000,
+			"bytes_in":   max(ctx.Request.ContentLength, 0),
+			"bytes_out":  max(writer.Size(), 0),
+			"client_ip":  trustedClientIP(ctx),
+			"user_agent": ctx.Request.UserAgent(),
+		}
+		if requestId := RequestIdFromContext(ctx.Request.Context()); requestId != "" {
+			fields["request_id"] = requestId
+		}
+		if traceId := requestTraceId(ctx); traceId != "" {
+			fields["trace_id"] = traceId
+		}
+		if principal, ok := PrincipalFromContext(ctx.Request.Context()); ok && principal != nil {
+			fields["principal"] = principal.Subject()
+		}
+		if status != httpStatus {
+			fields["http_status"] = httpStatus
+		}
+		if slow {
+			fields["slow"] = true
+		}
+		if config.Fields != nil {
+			for k, v := range config.Fields(&Request{ctx: ctx}) {
+				fields[k] = v
+			}
+		}
+
+		if config.Format == AccessLogText {
+			logger.Logrus().Log(currentLevel, accessLogText(fields))
+		} else {
+			logger.Logrus().WithFields(fields).Log(currentLevel, "access")
+		}
+	}
+}
+
+func accessLogExcluded(excludePaths []string, requestPath string) bool {
+	for _, exclude := range excludePaths {
+		if prefix, ok := strings.CutSuffix(exclude, "*"); ok {
+			if strings.HasPrefix(requestPath, prefix) {
+				return true
+			}
+		} else if requestPath == exclude {
+			return true
+		}
+	}
+	return false
+}
+
+// 格式: method path route status latency bytes_in bytes_out client_ip "user_agent" 其他字段以key=value追加
+func accessLogText(fields logrus.Fields) string {
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("%s %s %s %d %.3fms %d %d %s %q",
+		fields["method"], fields["path"], fields["route"], fields["status"], fields["latency_ms"],
+		fields["bytes_in"], fields["bytes_out"], fields["client_ip"], fields["user_agent"]))
+	for _, key := range []string{"http_status", "request_id", "trace_id", "principal", "slow"} {
+		if v, ok := fields[key]; ok {
+			builder.WriteString(fmt.Sprintf(" %s=%v", key, v))
+		}
+	}
+	for _, k := range slices.Sorted(maps.Keys(fields)) {
+		switch k {
+		case "method", "path", "route", "status", "latency_ms", "bytes_in", "bytes_out", "client_ip", "user_agent", "http_status", "request_id", "trace_id", "principal", "slow":
+		default:
+			builder.WriteString(fmt.Sprintf(" %s=%v", k, fields[k]))
+		}
+	}
+	return builder.String()
+}
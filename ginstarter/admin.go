@@ -0,0 +1,268 @@
+package ginstarter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	runtimepprof "runtime/pprof"
+	"strings"
+	"time"
+
+	"github.com/acexy/golang-toolkit/logger"
+	"github.com/gin-gonic/gin"
+)
+
+/**
+运维诊断端点
+pprof/            pprof索引及各类profile
+goroutines        完整的协程堆栈
+buildinfo         构建信息
+runtime           运行时信息
+config            当前GinConfig 敏感字段已脱敏
+routes            路由表
+*/
+
+// AdminConfig 运维诊断端点配置 Guard LoopbackOnly ListenAddress均未设置时服务启动失败
+type AdminConfig struct {
+	// 路由分组路径 默认/admin
+	GroupPath string
+	// 独立的监听地址 如 127.0.0.1:6060 设置后仅通过该地址暴露 不注册到业务服务
+	ListenAddress string
+	// 仅允许来自本机的请求 基于连接的远端地址判断 不受X-Forwarded-For影响
+	// 注册到业务服务时 经同一主机上的反向代理转发的请求远端地址均为本机 将全部被放行 此时应同时设置Guard
+	LoopbackOnly bool
+	// 访问保护 如BasicAuthInterceptor 不通过时中断请求
+	Guard PreInterceptor
+	// 禁用pprof端点
+	DisablePprof bool
+}
+
+var adminServer *http.Server
+
+var adminSecretFieldNames = []string{"secret", "password", "passwd", "token", "credential", "encryptionkey", "privatekey"}
+
+func initAdmin(engine *gin.Engine, config *AdminConfig) error {
+	adminServer = nil
+	if config == nil {
+		return nil
+	}
+	groupPath := config.GroupPath
+	if groupPath == "" {
+		groupPath = "/admin"
+	}
+	if config.Guard == nil && !config.LoopbackOnly {
+		if config.ListenAddress == "" {
+			return errors.New("admin endpoints require Guard, LoopbackOnly or a separate ListenAddress")
+		}
+		logger.Logrus().Warningln("admin endpoints are enabled without Guard or LoopbackOnly protection")
+	}
+	if config.ListenAddress == "" {
+		registerAdminHandlers(engine.Group(groupPath), config)
+		return nil
+	}
+	listener, err := net.Listen("tcp", config.ListenAddress)
+	if err != nil {
+		return err
+	}
+	adminEngine := gin.New()
+	adminEngine.Use(recoverHandler())
+	registerAdminHandlers(adminEngine.Group(groupPath), config)
+	server := &http.Server{Handler: adminEngine}
+	adminServer = server
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Logrus().Errorln("admin server stopped:", err)
+		}
+	}()
+	return nil
+}
+
+func stopAdmin(ctx context.Context) {
+	if adminServer != nil {
+		_ = adminServer.Shutdown(ctx)
+	}
+}
+
+func registerAdminHandlers(group *gin.RouterGroup, config *AdminConfig) {
+	group.Use(adminGuardHandler(config))
+	if !config.DisablePprof {
+		group.GET("pprof/", gin.WrapF(pprof.Index))
+		group.GET("pprof/cmdline", gin.WrapF(pprof.Cmdline))
+		group.GET("pprof/profile", gin.WrapF(pprof.Profile))
+		group.POST("pprof/symbol", gin.WrapF(pprof.Symbol))
+		group.GET("pprof/symbol", gin.WrapF(pprof.Symbol))
+		group.GET("pprof/trace", gin.WrapF(pprof.Trace))
+		group.GET("pprof/:name", func(ctx *gin.Context) {
+			pprof.Handler(ctx.Param("name")).ServeHTTP(ctx.Writer, ctx.Request)
+		})
+	}
+	group.GET("goroutines", func(ctx *gin.Context) {
+		ctx.Header("Content-Type", "text/plain; charset=utf-8")
+		ctx.Status(http.StatusOK)
+		_ = runtimepprof.Lookup("goroutine").WriteTo(ctx.Writer, 2)
+	})
+	group.GET("buildinfo", func(ctx *gin.Context) {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			ctx.Status(http.StatusNotFound)
+			return
+		}
+		settings := make(map[string]string, len(info.Settings))
+		for _, setting := range info.Settings {
+			settings[setting.Key] = setting.Value
+		}
+		deps := make([]string, 0, len(info.Deps))
+		for _, dep := range info.Deps {
+			deps = append(deps, dep.Path+"@"+dep.Version)
+		}
+		ctx.IndentedJSON(http.StatusOK, gin.H{
+			"goVersion": info.GoVersion,
+			"path":      info.Path,
+			"main":      info.Main.Path + "@" + info.Main.Version,
+			"settings":  settings,
+			"deps":      deps,
+		})
+	})
+	group.GET("runtime", func(ctx *gin.Context) {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		ctx.IndentedJSON(http.StatusOK, gin.H{
+			"goVersion":    runtime.Version(),
+			"os":           runtime.GOOS,
+			"arch":         runtime.GOARCH,
+			"numCpu":       runtime.NumCPU(),
+			"gomaxprocs":   runtime.GOMAXPROCS(0),
+			"numGoroutine": runtime.NumGoroutine(),
+			"heapAlloc":    stats.HeapAlloc,
+			"heapInuse":    stats.HeapInuse,
+			"sys":          stats.Sys,
+			"numGc":        stats.NumGC,
+			"pauseTotal":   time.Duration(stats.PauseTotalNs).String(),
+		})
+	})
+	group.GET("config", func(ctx *gin.Context) {
+		ctx.IndentedJSON(http.StatusOK, redactConfig(reflect.ValueOf(ginConfig), "", 0))
+	})
+	group.GET("routes", func(ctx *gin.Context) {
+		ctx.IndentedJSON(http.StatusOK, Routes())
+	})
+}
+
+// 访问保护中间件
+func adminGuardHandler(config *AdminConfig) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if config.LoopbackOnly {
+			host, _, err := net.SplitHostPort(ctx.Request.RemoteAddr)
+			ip := net.ParseIP(host)
+			if err != nil || ip == nil || !ip.IsLoopback() {
+				logger.Logrus().Warningln("admin endpoint rejected remote address:", ctx.Request.RemoteAddr)
+				httpResponse(ctx, RespHttpStatusCode(http.StatusForbidden))
+				ctx.Abort()
+				return
+			}
+		}
+		if config.Guard != nil {
+			response, _, continueHandler := config.Guard(&Request{ctx: ctx})
+			if response != nil {
+				httpResponse(ctx, response)
+			}
+			if !continueHandler {
+				ctx.Abort()
+				return
+			}
+		}
+		ctx.Next()
+	}
+}
+
+// 将配置转换为可序列化的结构 函数及接口仅输出类型 敏感字段脱敏
+func redactConfig(value reflect.Value, name string, depth int) any {
+	if depth > 6 {
+		return "..."
+	}
+	if isAdminSecretField(name) {
+		if value.IsValid() && !value.IsZero() {
+			return "******"
+		}
+		return nil
+	}
+	switch value.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Pointer:
+		if value.IsNil() {
+			return nil
+		}
+		return redactConfig(value.Elem(), name, depth+1)
+	case reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		switch value.Elem().Kind() {
+		case reflect.Pointer, reflect.Struct, reflect.Func, reflect.Map:
+			return value.Elem().Type().String()
+		}
+		return redactConfig(value.Elem(), name, depth+1)
+	case reflect.Func, reflect.Chan:
+		if value.IsNil() {
+			return nil
+		}
+		return value.Type().String()
+	case reflect.Struct:
+		result := make(map[string]any)
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			result[field.Name] = redactConfig(value.Field(i), field.Name, depth+1)
+		}
+		return result
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return nil
+		}
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return "<" + value.Type().String() + ">"
+		}
+		result := make([]any, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			result[i] = redactConfig(value.Index(i), name, depth+1)
+		}
+		return result
+	case reflect.Map:
+		if value.IsNil() {
+			return nil
+		}
+		result := make(map[string]any)
+		iter := value.MapRange()
+		for iter.Next() {
+			keyName := fmt.Sprint(iter.Key().Interface())
+			result[keyName] = redactConfig(iter.Value(), keyName, depth+1)
+		}
+		return result
+	case reflect.Int64:
+		if value.Type() == reflect.TypeOf(time.Duration(0)) {
+			return time.Duration(value.Int()).String()
+		}
+		return value.Int()
+	default:
+		return value.Interface()
+	}
+}
+
+func isAdminSecretField(name string) bool {
+	lower := strings.ToLower(name)
+	for _, secret := range adminSecretFieldNames {
+		if strings.Contains(lower, secret) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,236 @@
+package ginstarter
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/acexy/golang-toolkit/logger"
+	"github.com/gin-gonic/gin"
+)
+
+/**
+路由访问授权
+通过RouterInfo.Authorization及RouterWrapper.WithAuthorization声明 在认证拦截器绑定认证主体后由Authorizer校验
+未认证时响应StatusCodeUnauthorized 授权不通过时响应StatusCodeForbidden
+*/
+
+// Authorization 访问授权要求 各项均需满足
+// 权限支持使用{name}占位符声明资源范围 校验时使用同名路径参数替换 如 project:{id}:write
+type Authorization struct {
+	// 需要同时具备的角色
+	Roles []string
+	// 需要具备其中任意一个的角色
+	AnyRoles []string
+	// 需要同时具备的权限
+	Permissions []string
+	// 需要具备其中任意一个的权限
+	AnyPermissions []string
+}
+
+// RequireRoles 需要同时具备所有角色
+func RequireRoles(roles ...string) *Authorization {
+	return &Authorization{Roles: roles}
+}
+
+// RequireAnyRole 需要具备任意一个角色
+func RequireAnyRole(roles ...string) *Authorization {
+	return &Authorization{AnyRoles: roles}
+}
+
+// RequirePermissions 需要同时具备所有权限
+func RequirePermissions(permissions ...string) *Authorization {
+	return &Authorization{Permissions: permissions}
+}
+
+// RequireAnyPermission 需要具备任意一个权限
+func RequireAnyPermission(permissions ...string) *Authorization {
+	return &Authorization{AnyPermissions: permissions}
+}
+
+func (a *Authorization) String() string {
+	var items []string
+	if len(a.Roles) > 0 {
+		items = append(items, "roles="+strings.Join(a.Roles, ","))
+	}
+	if len(a.AnyRoles) > 0 {
+		items = append(items, "any-roles="+strings.Join(a.AnyRoles, "|"))
+	}
+	if len(a.Permissions) > 0 {
+		items = append(items, "permissions="+strings.Join(a.Permissions, ","))
+	}
+	if len(a.AnyPermissions) > 0 {
+		items = append(items, "any-permissions="+strings.Join(a.AnyPermissions, "|"))
+	}
+	return strings.Join(items, " ")
+}
+
+// Authorizer 授权校验器 可自定义实现从数据库或权限中心获取主体的角色及权限
+type Authorizer interface {
+	// HasRole 认证主体是否具备角色
+	HasRole(request *Request, principal Principal, role string) (bool, error)
+	// HasPermission 认证主体是否具备权限 permission中的占位符已被替换
+	HasPermission(request *Request, principal Principal, permission string) (bool, error)
+}
+
+// RolesPrincipal 携带角色信息的认证主体 用于默认的Authorizer
+type RolesPrincipal interface {
+	Principal
+	Roles() []string
+}
+
+// PermissionsPrincipal 携带权限信息的认证主体 用于默认的Authorizer
+type PermissionsPrincipal interface {
+	Principal
+	Permissions() []string
+}
+
+// 默认授权校验器 使用认证主体自身携带的角色及权限
+type defaultAuthorizer struct {
+}
+
+func (d defaultAuthorizer) HasRole(_ *Request, principal Principal, role string) (bool, error) {
+	if p, ok := principal.(RolesPrincipal); ok {
+		for _, v := range p.Roles() {
+			if v == role {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (d defaultAuthorizer) HasPermission(_ *Request, principal Principal, permission string) (bool, error) {
+	if p, ok := principal.(PermissionsPrincipal); ok {
+		for _, v := range p.Permissions() {
+			if MatchPermission(v, permission) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// MatchPermission 判断已授予的权限是否满足要求的权限
+// 权限以:分段 授予权限中的*匹配任意单个分段 末尾的*匹配剩余所有分段 如 order:* 满足 order:1:read
+func MatchPermission(granted, required string) bool {
+	if granted == required || granted == "*" {
+		return true
+	}
+	grantedParts := strings.Split(granted, ":")
+	requiredParts := strings.Split(required, ":")
+	for i, part := range grantedParts {
+		if i >= len(requiredParts) {
+			return false
+		}
+		if part == "*" {
+			if i == len(grantedParts)-1 {
+				return true
+			}
+			continue
+		}
+		if part != requiredParts[i] {
+			return false
+		}
+	}
+	return len(grantedParts) == len(requiredParts)
+}
+
+// 使用路径参数替换权限中的{name}占位符
+func resolvePermission(request *Request, permission string) string {
+	if !strings.Contains(permission, "{") {
+		return permission
+	}
+	builder := strings.Builder{}
+	for {
+		start := strings.Index(permission, "{")
+		if start == -1 {
+			break
+		}
+		end := strings.Index(permission[start:], "}")
+		if end == -1 {
+			break
+		}
+		builder.WriteString(permission[:start])
+		builder.WriteString(request.GetPathParam(permission[start+1 : start+end]))
+		permission = permission[start+end+1:]
+	}
+	builder.WriteString(permission)
+	return builder.String()
+}
+
+func (a *Authorization) evaluate(request *Request, principal Principal, authorizer Authorizer) (bool, error) {
+	for _, role := range a.Roles {
+		if ok, err := authorizer.HasRole(request, principal, role); err != nil || !ok {
+			return false, err
+		}
+	}
+	if len(a.AnyRoles) > 0 {
+		matched := false
+		for _, role := range a.AnyRoles {
+			ok, err := authorizer.HasRole(request, principal, role)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	for _, permission := range a.Permissions {
+		if ok, err := authorizer.HasPermission(request, principal, resolvePermission(request, permission)); err != nil || !ok {
+			return false, err
+		}
+	}
+	if len(a.AnyPermissions) > 0 {
+		for _, permission := range a.AnyPermissions {
+			ok, err := authorizer.HasPermission(request, principal, resolvePermission(request, permission))
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// 路由授权校验
+func authorizationHandler(authorizations []*Authorization) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if v, exists := ctx.Get(ginCtxKeyContinueHandler); exists && !v.(bool) {
+			return
+		}
+		request := &Request{ctx: ctx}
+		principal, ok := request.Principal()
+		if !ok || principal == nil {
+			httpResponse(ctx, RespHttpStatusCode(http.StatusUnauthorized))
+			ctx.Abort()
+			return
+		}
+		authorizer := ginConfig.Authorizer
+		if authorizer == nil {
+			authorizer = defaultAuthorizer{}
+		}
+		for _, authorization := range authorizations {
+			allowed, err := authorization.evaluate(request, principal, authorizer)
+			if err != nil {
+				logger.Logrus().Errorln("authorize error path:", request.RequestPath(), err)
+				httpResponse(ctx, RespHttpStatusCode(http.StatusInternalServerError))
+				ctx.Abort()
+				return
+			}
+			if !allowed {
+				logger.Logrus().Warningln("access denied path:", request.RequestPath(), "subject:", principal.Subject(), "require:", authorization.String())
+				httpResponse(ctx, RespHttpStatusCode(http.StatusForbidden))
+				ctx.Abort()
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package ginstarter
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/acexy/golang-toolkit/logger"
+	"github.com/sirupsen/logrus"
+)
+
+var bizErrorRegistry = make(map[BizErrorCode]*BizError)
+var bizErrorRegistryMutex sync.RWMutex
+
+// BizError 预定义的业务错误 HandlerWrapper返回该错误(或包裹了该错误)时将响应标准Rest业务错误
+// 业务错误属于预期内错误 不会触发panic流程及打印堆栈
+//
+//	var ErrOrderNotFound = ginstarter.RegisterBizError(10001, "order {0} not found")
+//	return nil, ErrOrderNotFound.With(orderId)
+type BizError struct {
+	code    BizErrorCode
+	message BizErrorMessage
+
+	statusCode     StatusCode
+	httpStatusCode int
+	logLevel       logrus.Level
+
+	params []string
+	cause  error
+}
+
+// BizErrorOption 业务错误注册选项
+type BizErrorOption func(bizError *BizError)
+
+// BizErrorStatusCode 注册时设置响应的Rest状态码 默认 StatusCodeSuccess
+func BizErrorStatusCode(statusCode StatusCode) BizErrorOption {
+	return func(bizError *BizError) {
+		bizError.statusCode = statusCode
+	}
+}
+
+// BizErrorHttpStatusCode 注册时设置响应的http状态码 默认 200
+func BizErrorHttpStatusCode(httpStatusCode int) BizErrorOption {
+	return func(bizError *BizError) {
+		bizError.httpStatusCode = httpStatusCode
+	}
+}
+
+// BizErrorLogLevel 注册时设置该业务错误触发时的日志级别 默认 Info
+func BizErrorLogLevel(level logrus.Level) BizErrorOption {
+	return func(bizError *BizError) {
+		bizError.logLevel = level
+	}
+}
+
+// RegisterBizError 注册业务错误 message 为默认错误信息 可以使用{0} {1}作为参数占位符
+// 启用国际化时优先使用I18nConfig.BizErrorMessages中的错误信息
+// 重复注册相同的错误码将触发panic
+//
+//	var ErrOrderNotFound = ginstarter.RegisterBizError(10001, "order {0} not found", ginstarter.BizErrorHttpStatusCode(http.StatusNotFound))
+func RegisterBizError(code BizErrorCode, message BizErrorMessage, options ...BizErrorOption) *BizError {
+	bizErrorRegistryMutex.Lock()
+	defer bizErrorRegistryMutex.Unlock()
+	if _, ok := bizErrorRegistry[code]; ok {
+		panic("duplicate biz error code: " + strconv.Itoa(int(code)))
+	}
+	bizError := &BizError{
+		code:           code,
+		message:        message,
+		statusCode:     StatusCodeSuccess,
+		httpStatusCode: http.StatusOK,
+		logLevel:       logrus.InfoLevel,
+	}
+	for _, option := range options {
+		option(bizError)
+	}
+	bizErrorRegistry[code] = bizError
+	return bizError
+}
+
+// LookupBizError 根据错误码获取已注册的业务错误
+func LookupBizError(code BizErrorCode) (*BizError, bool) {
+	bizErrorRegistryMutex.RLock()
+	defer bizErrorRegistryMutex.RUnlock()
+	bizError, ok := bizErrorRegistry[code]
+	return bizError, ok
+}
+
+// SetStatusCode 创建使用指定Rest状态码的业务错误 不修改已注册的业务错误
+func (e *BizError) SetStatusCode(statusCode StatusCode) *BizError {
+	bizError := *e
+	bizError.statusCode = statusCode
+	return &bizError
+}
+
+// SetHttpStatusCode 创建使用指定http状态码的业务错误 不修改已注册的业务错误
+// 非200状态码同样响应业务错误结构 不由BadHttpCodeResolver处理
+func (e *BizError) SetHttpStatusCode(httpStatusCode int) *BizError {
+	bizError := *e
+	bizError.httpStatusCode = httpStatusCode
+	return &bizError
+}
+
+// SetLogLevel 创建使用指定日志级别的业务错误 不修改已注册的业务错误
+func (e *BizError) SetLogLevel(level logrus.Level) *BizError {
+	bizError := *e
+	bizError.logLevel = level
+	return &bizError
+}
+
+// With 创建携带错误信息参数的业务错误 参数依次替换错误信息中的{0} {1}占位符
+func (e *BizError) With(params ...any) *BizError {
+	bizError := *e
+	bizError.params = make([]string, len(params))
+	for i, param := range params {
+		bizError.params[i] = fmt.Sprint(param)
+	}
+	return &bizError
+}
+
+// Wrap 创建包裹原始错误的业务错误 原始错误仅用于日志记录
+func (e *BizError) Wrap(cause error) *BizError {
+	bizError := *e
+	bizError.cause = cause
+	return &bizError
+}
+
+// Code 业务错误码
+func (e *BizError) Code() BizErrorCode {
+	return e.code
+}
+
+// Message 默认语言的业务错误信息
+func (e *BizError) Message() BizErrorMessage {
+	return e.formatMessage(e.message)
+}
+
+func (e *BizError) Error() string {
+	message := "biz error " + strconv.Itoa(int(e.code)) + ": " + string(e.Message())
+	if e.cause != nil {
+		message += ": " + e.cause.Error()
+	}
+	return message
+}
+
+func (e *BizError) Unwrap() error {
+	return e.cause
+}
+
+// Is 相同错误码的业务错误视为同一错误
+func (e *BizError) Is(target error) bool {
+	if t, ok := target.(*BizError); ok {
+		return t.code == e.code
+	}
+	return false
+}
+
+// Response 根据请求语言生成标准Rest业务错误响应
+func (e *BizError) Response(request *Request) Response {
+	message, ok := localeBizErrorTemplate(request.ctx, e.code)
+	if !ok {
+		message = e.message
+	}
+	message = e.formatMessage(message)
+	code := e.code
+	dataRest := &RestRespStruct{
+		Status: &RestRespStatusStruct{
+			StatusCode:      e.statusCode,
+			StatusMessage:   request.StatusMessage(e.statusCode),
+			BizErrorCode:    &code,
+			BizErrorMessage: &message,
+			Timestamp:       time.Now().UnixMilli(),
+		},
+	}
+	response := NewRespRest()
+	responseData := response.SetData(dataRest).SetStatusCode(e.httpStatusCode)
+	// 业务错误已是完整的响应 非200状态码不由BadHttpCodeResolver改写
+	responseData.bypassResolver = true
+	return response
+}
+
+func (e *BizError) formatMessage(message BizErrorMessage) BizErrorMessage {
+	return formatBizErrorMessage(message, e.params...)
+}
+
+// 依次替换错误信息中的{0} {1}占位符 缺少的参数保留占位符
+func formatBizErrorMessage(message BizErrorMessage, params ...string) BizErrorMessage {
+	if len(params) == 0 {
+		return message
+	}
+	oldnew := make([]string, 0, len(params)*2)
+	for i, param := range params {
+		oldnew = append(oldnew, "{"+strconv.Itoa(i)+"}", param)
+	}
+	return BizErrorMessage(strings.NewReplacer(oldnew...).Replace(string(message)))
+}
+
+// 记录业务错误日志 不打印堆栈
+func (e *BizError) log(request *Request) {
+	logger.Logrus().Log(e.logLevel, "biz error path: ", request.RequestPath(), " ", e.Error())
+}
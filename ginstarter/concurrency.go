@@ -0,0 +1,267 @@
+package ginstarter
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/acexy/golang-toolkit/logger"
+	"github.com/gin-gonic/gin"
+)
+
+/**
+并发限制及自适应负载削减
+可作用于全局(GinConfig.ConcurrencyLimit) 路由分组(RouterInfo.ConcurrencyLimit) 及单个路由(RouterWrapper.WithConcurrencyLimit)
+超出限制的请求将短暂排队 排队超时或队列已满时通过BadHttpCodeResolver响应StatusCodeServiceUnavailable
+*/
+
+// RequestPriority 请求优先级 过载时优先削减低优先级请求
+type RequestPriority int
+
+const (
+	// RequestPriorityLow 低优先级 仅可使用部分并发额度 且不参与排队
+	RequestPriorityLow RequestPriority = -1
+	// RequestPriorityNormal 默认优先级
+	RequestPriorityNormal RequestPriority = 0
+	// RequestPriorityHigh 高优先级 优先获取并发额度
+	RequestPriorityHigh RequestPriority = 1
+)
+
+type AdaptiveLimitAlgorithm int
+
+const (
+	// AdaptiveLimitAIMD 请求耗时超过阈值时乘性减少并发限制 否则加性增加
+	AdaptiveLimitAIMD AdaptiveLimitAlgorithm = iota
+	// AdaptiveLimitGradient 根据请求耗时与最小耗时的比值调整并发限制
+	AdaptiveLimitGradient
+)
+
+const gradientMinLatencyResetSamples = 1000
+
+// ConcurrencyLimitConfig 并发限制配置
+type ConcurrencyLimitConfig struct {
+	// 最大并发请求数 启用自适应时为初始值
+	MaxConcurrency int
+	// 超出并发限制时的最大排队等待时间 0表示不排队
+	MaxWait time.Duration
+	// 最大排队请求数 默认等于MaxConcurrency
+	MaxQueue int
+	// 低优先级请求可使用的并发比例 默认0.8
+	LowPriorityRatio float64
+	// 拒绝请求时响应的Retry-After 默认1秒
+	RetryAfter time.Duration
+	// 自定义请求优先级 默认使用路由注册的优先级
+	Priority func(request *Request) RequestPriority
+	// 自适应并发限制 根据请求耗时动态调整并发限制
+	Adaptive *AdaptiveLimitConfig
+}
+
+// AdaptiveLimitConfig 自适应并发限制配置
+type AdaptiveLimitConfig struct {
+	// 调整算法 默认AIMD
+	Algorithm AdaptiveLimitAlgorithm
+	// 并发限制下限 默认1
+	MinConcurrency int
+	// 并发限制上限 默认为MaxConcurrency的10倍
+	MaxConcurrency int
+
+	// AIMD 请求耗时超过该阈值视为过载 默认1秒
+	LatencyThreshold time.Duration
+	// AIMD 过载时并发限制的缩减系数 默认0.9
+	BackoffRatio float64
+
+	// Gradient 可容忍的请求耗时相对最小耗时的倍数 默认2
+	Tolerance float64
+}
+
+type concurrencyWaiter struct {
+	ready    chan struct{}
+	priority RequestPriority
+}
+
+type concurrencyLimiter struct {
+	config   ConcurrencyLimitConfig
+	adaptive *AdaptiveLimitConfig
+
+	mutex    sync.Mutex
+	limit    float64
+	inflight int
+	waiters  []*concurrencyWaiter
+
+	// Gradient
+	minLatency time.Duration
+	samples    int
+}
+
+func newConcurrencyLimiter(config *ConcurrencyLimitConfig) *concurrencyLimiter {
+	limiter := &concurrencyLimiter{config: *config}
+	c := &limiter.config
+	if c.MaxConcurrency <= 0 {
+		c.MaxConcurrency = 1
+	}
+	if c.MaxQueue <= 0 {
+		c.MaxQueue = c.MaxConcurrency
+	}
+	if c.LowPriorityRatio <= 0 || c.LowPriorityRatio > 1 {
+		c.LowPriorityRatio = 0.8
+	}
+	if c.RetryAfter <= 0 {
+		c.RetryAfter = time.Second
+	}
+	if c.Adaptive != nil {
+		adaptive := *c.Adaptive
+		if adaptive.MinConcurrency <= 0 {
+			adaptive.MinConcurrency = 1
+		}
+		if adaptive.MaxConcurrency <= 0 {
+			adaptive.MaxConcurrency = c.MaxConcurrency * 10
+		}
+		if adaptive.LatencyThreshold <= 0 {
+			adaptive.LatencyThreshold = time.Second
+		}
+		if adaptive.BackoffRatio <= 0 || adaptive.BackoffRatio >= 1 {
+			adaptive.BackoffRatio = 0.9
+		}
+		if adaptive.Tolerance < 1 {
+			adaptive.Tolerance = 2
+		}
+		limiter.adaptive = &adaptive
+	}
+	limiter.limit = float64(c.MaxConcurrency)
+	return limiter
+}
+
+// 并发限制中间件
+func (l *concurrencyLimiter) handler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if v, exists := ctx.Get(ginCtxKeyContinueHandler); exists && !v.(bool) {
+			ctx.Next()
+			return
+		}
+		request := &Request{ctx: ctx}
+		priority := l.priority(request)
+		if !l.acquire(ctx.Request.Context(), priority) {
+			logger.Logrus().Warningln("request shed path:", request.RequestPath(), "priority:", priority)
+			ctx.Header("Retry-After", strconv.Itoa(ceilSeconds(l.config.RetryAfter)))
+			httpResponse(ctx, httpStatusResponse(request, http.StatusServiceUnavailable))
+			ctx.Abort()
+			return
+		}
+		start := time.Now()
+		defer releaseAfterHandler(ctx, func() {
+			l.release(time.Since(start))
+		})
+		ctx.Next()
+	}
+}
+
+func (l *concurrencyLimiter) priority(request *Request) RequestPriority {
+	if l.config.Priority != nil {
+		return l.config.Priority(request)
+	}
+	if route := lookupRoute(request.HttpMethod(), request.RouterFullPath()); route != nil {
+		return route.Priority
+	}
+	return RequestPriorityNormal
+}
+
+// 当前优先级是否可直接获取并发额度
+func (l *concurrencyLimiter) available(priority RequestPriority) bool {
+	limit := int(l.limit)
+	switch {
+	case priority < RequestPriorityNormal:
+		return l.inflight < max(1, int(float64(limit)*l.config.LowPriorityRatio))
+	case priority > RequestPriorityNormal:
+		return l.inflight < limit
+	default:
+		return l.inflight < limit && len(l.waiters) == 0
+	}
+}
+
+// 获取并发额度 超出限制时排队等待
+func (l *concurrencyLimiter) acquire(ctx context.Context, priority RequestPriority) bool {
+	l.mutex.Lock()
+	if l.available(priority) {
+		l.inflight++
+		l.mutex.Unlock()
+		return true
+	}
+	if priority < RequestPriorityNormal || l.config.MaxWait <= 0 || len(l.waiters) >= l.config.MaxQueue {
+		l.mutex.Unlock()
+		return false
+	}
+	waiter := &concurrencyWaiter{ready: make(chan struct{}), priority: priority}
+	l.waiters = append(l.waiters, waiter)
+	l.mutex.Unlock()
+	logger.Logrus().Debugln("request queued priority:", priority)
+
+	timer := time.NewTimer(l.config.MaxWait)
+	defer timer.Stop()
+	select {
+	case <-waiter.ready:
+		return true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for i, w := range l.waiters {
+		if w == waiter {
+			l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+			return false
+		}
+	}
+	// 已在超时的同时获取到额度
+	return true
+}
+
+// 释放并发额度 并根据请求耗时调整并发限制
+func (l *concurrencyLimiter) release(latency time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.adaptive != nil {
+		l.adapt(latency)
+	}
+	l.inflight--
+	for len(l.waiters) > 0 && l.inflight < int(l.limit) {
+		index := 0
+		for i, w := range l.waiters {
+			if w.priority > l.waiters[index].priority {
+				index = i
+			}
+		}
+		waiter := l.waiters[index]
+		l.waiters = append(l.waiters[:index], l.waiters[index+1:]...)
+		l.inflight++
+		close(waiter.ready)
+	}
+}
+
+func (l *concurrencyLimiter) adapt(latency time.Duration) {
+	adaptive := l.adaptive
+	limit := l.limit
+	switch adaptive.Algorithm {
+	case AdaptiveLimitGradient:
+		l.samples++
+		if l.minLatency == 0 || latency < l.minLatency || l.samples >= gradientMinLatencyResetSamples {
+			l.minLatency = latency
+			l.samples = 0
+		}
+		if latency <= 0 {
+			return
+		}
+		gradient := math.Max(0.5, math.Min(1, adaptive.Tolerance*float64(l.minLatency)/float64(latency)))
+		newLimit := limit*gradient + math.Sqrt(limit)
+		limit = limit*0.8 + newLimit*0.2
+	default:
+		if latency > adaptive.LatencyThreshold {
+			limit = limit * adaptive.BackoffRatio
+		} else if float64(l.inflight)*2 >= limit {
+			limit = limit + 1/limit
+		}
+	}
+	l.limit = math.Max(float64(adaptive.MinConcurrency), math.Min(float64(adaptive.MaxConcurrency), limit))
+}
@@ -8,8 +8,15 @@ type BizErrorCode int
 type BizErrorMessage string
 
 const (
-	ginCtxKeyCurrentResponse = "_internal_response"
-	ginCtxKeyContinueHandler = "_internal_continue_handler"
+	ginCtxKeyCurrentResponse  = "_internal_response"
+	ginCtxKeyContinueHandler  = "_internal_continue_handler"
+	ginCtxKeyLocale           = "_internal_locale"
+	ginCtxKeySession          = "_internal_session"
+	ginCtxKeyCsrf             = "_internal_csrf"
+	ginCtxKeyResolvedStatus   = "_internal_resolved_status"
+	ginCtxKeyPanic            = "_internal_panic"
+	ginCtxKeyAbandonedHandler = "_internal_abandoned_handler"
+	ginCtxKeyBypassResolver   = "_internal_bypass_resolver"
 )
 const (
 	StatusCodeSuccess            = http.StatusOK
@@ -0,0 +1,109 @@
+package ginstarter
+
+import (
+	"context"
+	"net"
+
+	"github.com/gin-gonic/gin"
+)
+
+var serverContext context.Context
+var serverCancel context.CancelFunc
+
+var contextKeyTraceId = NewContextKey[string]("traceId")
+var contextKeyPrincipal = NewContextKey[Principal]("principal")
+
+// ContextKey 类型安全的请求上下文键 值存储于Request.Context()中 可在下游调用中通过Value获取
+//
+//	var ContextKeyTenant = ginstarter.NewContextKey[string]("tenant")
+//	ContextKeyTenant.Set(request, "t1")
+//	tenant, ok := ContextKeyTenant.Value(ctx)
+type ContextKey[T any] struct {
+	name string
+}
+
+// NewContextKey 创建类型安全的请求上下文键 每次调用均创建不同的键
+func NewContextKey[T any](name string) *ContextKey[T] {
+	return &ContextKey[T]{name: name}
+}
+
+func (k *ContextKey[T]) String() string {
+	return "ginstarter context key " + k.name
+}
+
+// Set 向请求上下文绑定数据
+func (k *ContextKey[T]) Set(request *Request, value T) {
+	request.SetContext(context.WithValue(request.Context(), k, value))
+}
+
+// Get 从请求上下文获取数据
+func (k *ContextKey[T]) Get(request *Request) (T, bool) {
+	return k.Value(request.Context())
+}
+
+// Value 从任意派生自请求上下文的context.Context中获取数据
+func (k *ContextKey[T]) Value(ctx context.Context) (T, bool) {
+	v, ok := ctx.Value(k).(T)
+	return v, ok
+}
+
+// Principal 认证主体 由认证拦截器绑定到请求上下文
+type Principal interface {
+	// Subject 主体唯一标识
+	Subject() string
+}
+
+// PrincipalAs 获取指定类型的认证主体
+func PrincipalAs[T Principal](request *Request) (T, bool) {
+	principal, _ := request.Principal()
+	v, ok := principal.(T)
+	return v, ok
+}
+
+// PrincipalFromContext 从派生自请求上下文的context.Context中获取认证主体
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	return contextKeyPrincipal.Value(ctx)
+}
+
+// TraceIdFromContext 从派生自请求上下文的context.Context中获取TraceId
+func TraceIdFromContext(ctx context.Context) string {
+	traceId, _ := contextKeyTraceId.Value(ctx)
+	return traceId
+}
+
+func traceIdHeaderName() string {
+	if ginConfig == nil || ginConfig.TraceIdHeaderName == "" {
+		return "Trace-Id"
+	}
+	return ginConfig.TraceIdHeaderName
+}
+
+// 服务的基础上下文 服务停止时取消
+func baseContext(_ net.Listener) context.Context {
+	return serverContext
+}
+
+// 获取当前请求的TraceId 未设置且启用了TraceIdResponse时生成并绑定到请求上下文
+func requestTraceId(ctx *gin.Context) string {
+	if traceId, ok := contextKeyTraceId.Value(ctx.Request.Context()); ok {
+		return traceId
+	}
+	if ginConfig == nil || ginConfig.TraceIdResponse == nil {
+		return ""
+	}
+	traceId := ginConfig.TraceIdResponse()
+	ctx.Request = ctx.Request.WithContext(context.WithValue(ctx.Request.Context(), contextKeyTraceId, traceId))
+	return traceId
+}
+
+// 创建服务基础上下文
+func newServerContext() {
+	serverContext, serverCancel = context.WithCancel(context.Background())
+}
+
+// 取消服务基础上下文 所有未完成请求的上下文将被取消
+func cancelServerContext() {
+	if serverCancel != nil {
+		serverCancel()
+	}
+}
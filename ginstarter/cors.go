@@ -0,0 +1,190 @@
+package ginstarter
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**
+跨域资源共享
+可全局配置(GinConfig.CorsConfig)或按Router配置(RouterInfo.Cors) Router配置优先
+preflight请求在路由匹配及405处理之前直接响应
+*/
+
+// CorsConfig 跨域配置
+type CorsConfig struct {
+	// 允许的来源 支持*通配 如 https://*.example.com 仅配置*时允许所有来源
+	AllowOrigins []string
+	// 允许的来源正则表达式
+	AllowOriginPatterns []string
+	// 自定义来源校验 优先于AllowOrigins及AllowOriginPatterns
+	AllowOriginFunc func(origin string) bool
+	// 允许的请求方法 默认GET POST PUT PATCH DELETE HEAD
+	AllowMethods []string
+	// 允许的请求头 默认允许preflight请求声明的所有请求头
+	AllowHeaders []string
+	// 允许浏览器读取的响应头
+	ExposeHeaders []string
+	// 允许携带Cookie等凭证
+	AllowCredentials bool
+	// preflight结果缓存时间
+	MaxAge time.Duration
+}
+
+type corsPolicy struct {
+	config        *CorsConfig
+	allowAll      bool
+	origins       map[string]bool
+	originRegexps []*regexp.Regexp
+	allowMethods  string
+	allowHeaders  string
+	exposeHeaders string
+	maxAge        string
+}
+
+var globalCorsPolicy *corsPolicy
+var routerCorsPolicies []*routerScoped[*corsPolicy]
+
+func newCorsPolicy(config *CorsConfig) (*corsPolicy, error) {
+	policy := &corsPolicy{
+		config:  config,
+		origins: make(map[string]bool),
+	}
+	for _, origin := range config.AllowOrigins {
+		if origin == "*" {
+			policy.allowAll = true
+		} else if strings.Contains(origin, "*") {
+			pattern := "^" + strings.ReplaceAll(regexp.QuoteMeta(strings.ToLower(origin)), `\*`, `[a-z0-9.-]+`) + "$"
+			policy.originRegexps = append(policy.originRegexps, regexp.MustCompile(pattern))
+		} else {
+			policy.origins[strings.ToLower(origin)] = true
+		}
+	}
+	for _, pattern := range config.AllowOriginPatterns {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		policy.originRegexps = append(policy.originRegexps, compiled)
+	}
+	methods := config.AllowMethods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead}
+	}
+	policy.allowMethods = strings.ToUpper(strings.Join(methods, ", "))
+	policy.allowHeaders = strings.Join(config.AllowHeaders, ", ")
+	policy.exposeHeaders = strings.Join(config.ExposeHeaders, ", ")
+	if config.MaxAge > 0 {
+		policy.maxAge = strconv.Itoa(int(config.MaxAge.Seconds()))
+	}
+	return policy, nil
+}
+
+func (p *corsPolicy) allowOrigin(origin string) bool {
+	if p.config.AllowOriginFunc != nil {
+		return p.config.AllowOriginFunc(origin)
+	}
+	if p.allowAll {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if p.origins[origin] {
+		return true
+	}
+	for _, compiled := range p.originRegexps {
+		if compiled.MatchString(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// 初始化全局跨域配置
+func initCors(config *CorsConfig) error {
+	globalCorsPolicy = nil
+	routerCorsPolicies = nil
+	if config == nil {
+		return nil
+	}
+	policy, err := newCorsPolicy(config)
+	if err != nil {
+		return err
+	}
+	globalCorsPolicy = policy
+	return nil
+}
+
+// 注册Router级别跨域配置
+func registerRouterCors(groupPath string, config *CorsConfig) error {
+	policy, err := newCorsPolicy(config)
+	if err != nil {
+		return err
+	}
+	routerCorsPolicies = append(routerCorsPolicies, newRouterScoped(groupPath, policy))
+	return nil
+}
+
+// 按照请求路径匹配Router配置 未匹配时使用全局配置
+func matchCorsPolicy(path string) *corsPolicy {
+	if policy, ok := matchRouterScoped(routerCorsPolicies, path); ok {
+		return policy
+	}
+	return globalCorsPolicy
+}
+
+// 跨域中间件 作为全局中间件注册 同样作用于404及405处理
+func corsHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		origin := ctx.GetHeader("Origin")
+		if origin == "" {
+			return
+		}
+		policy := matchCorsPolicy(ctx.Request.URL.Path)
+		if policy == nil {
+			return
+		}
+		preflight := ctx.Request.Method == http.MethodOptions && ctx.GetHeader("Access-Control-Request-Method") != ""
+		header := ctx.Writer.Header()
+		header.Add("Vary", "Origin")
+		if !policy.allowOrigin(origin) {
+			if preflight {
+				httpResponse(ctx, RespHttpStatusCode(http.StatusForbidden))
+				ctx.Abort()
+			}
+			return
+		}
+		if policy.allowAll && !policy.config.AllowCredentials && policy.config.AllowOriginFunc == nil {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if policy.config.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+		if !preflight {
+			if policy.exposeHeaders != "" {
+				header.Set("Access-Control-Expose-Headers", policy.exposeHeaders)
+			}
+			return
+		}
+		header.Add("Vary", "Access-Control-Request-Method")
+		header.Add("Vary", "Access-Control-Request-Headers")
+		header.Set("Access-Control-Allow-Methods", policy.allowMethods)
+		if policy.allowHeaders != "" {
+			header.Set("Access-Control-Allow-Headers", policy.allowHeaders)
+		} else if requestHeaders := ctx.GetHeader("Access-Control-Request-Headers"); requestHeaders != "" {
+			header.Set("Access-Control-Allow-Headers", requestHeaders)
+		}
+		if policy.maxAge != "" {
+			header.Set("Access-Control-Max-Age", policy.maxAge)
+		}
+		// preflight响应不由BadHttpCodeResolver改写
+		ctx.Set(ginCtxKeyBypassResolver, true)
+		ctx.AbortWithStatus(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,252 @@
+package ginstarter
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/acexy/golang-toolkit/logger"
+)
+
+/**
+CSRF防护拦截器
+双重提交Cookie模式: token保存在非HttpOnly的Cookie中 请求时需通过请求头或表单字段再次提交
+同步令牌模式: token保存在会话中 需配置GinConfig.SessionConfig
+通过RouterInfo.Csrf启用时在启动时校验会话配置 直接作为PreInterceptor使用时未启用会话的请求将响应500
+安全方法(GET HEAD OPTIONS TRACE)不校验 已下发token或会话已存在时通过响应头暴露token
+匿名请求不会主动下发token 需要时由handler调用Request.CsrfToken()生成
+*/
+
+const sessionCsrfKey = "_csrf"
+
+type CsrfMode int
+
+const (
+	// CsrfDoubleSubmitCookie 双重提交Cookie
+	CsrfDoubleSubmitCookie CsrfMode = iota
+	// CsrfSynchronizerToken 同步令牌 token保存在会话中
+	CsrfSynchronizerToken
+)
+
+// CsrfConfig CSRF防护配置
+type CsrfConfig struct {
+	// 防护模式 默认双重提交Cookie
+	Mode CsrfMode
+	// 签名密钥 双重提交Cookie模式下设置后token将被签名 防止Cookie被注入任意值
+	SecretKey []byte
+
+	// 提交token的请求头 同时用于在安全方法的响应中暴露token 默认X-CSRF-Token
+	HeaderName string
+	// 提交token的表单字段 默认_csrf
+	FormFieldName string
+
+	// 双重提交Cookie名称 默认csrf_token
+	CookieName string
+	// Cookie路径 默认/
+	CookiePath   string
+	CookieDomain string
+	CookieSecure bool
+	// 默认http.SameSiteLaxMode
+	CookieSameSite http.SameSite
+
+	// 受信任的跨域来源 需包含协议 如 https://admin.example.com 默认仅允许与请求协议及Host相同的来源
+	TrustedOrigins []string
+	// 禁用Origin/Referer校验
+	DisableOriginCheck bool
+}
+
+type csrfState struct {
+	config *CsrfConfig
+	token  string
+}
+
+// 注册Router的CSRF防护 同步令牌模式需启用会话
+func registerRouterCsrf(config *CsrfConfig) (PreInterceptor, error) {
+	if config.Mode == CsrfSynchronizerToken && sessionConfig == nil {
+		return nil, errors.New("csrf synchronizer token mode requires GinConfig.SessionConfig")
+	}
+	return CsrfInterceptor(config), nil
+}
+
+// CsrfInterceptor CSRF防护拦截器 校验失败时响应403
+// match 满足指定条件才执行
+func CsrfInterceptor(config *CsrfConfig, match ...func(request *Request) bool) PreInterceptor {
+	c := *config
+	if c.HeaderName == "" {
+		c.HeaderName = "X-CSRF-Token"
+	}
+	if c.FormFieldName == "" {
+		c.FormFieldName = "_csrf"
+	}
+	if c.CookieName == "" {
+		c.CookieName = "csrf_token"
+	}
+	if c.CookiePath == "" {
+		c.CookiePath = "/"
+	}
+	if c.CookieSameSite == 0 {
+		c.CookieSameSite = http.SameSiteLaxMode
+	}
+	return func(request *Request) (Response, bool, bool) {
+		if len(match) > 0 {
+			if !match[0](request) {
+				return nil, true, true
+			}
+		}
+		request.ctx.Set(ginCtxKeyCsrf, &csrfState{config: &c})
+		switch request.HttpMethod() {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			if csrfIssued(request, &c) {
+				request.CsrfToken()
+			}
+			return nil, true, true
+		}
+		if !c.DisableOriginCheck && !csrfTrustedOrigin(request, &c) {
+			logger.Logrus().Warningln("csrf origin rejected path:", request.RequestPath(), "origin:", request.GetHeader("Origin"), "referer:", request.GetHeader("Referer"))
+			return RespHttpStatusCode(http.StatusForbidden), false, false
+		}
+		expected := csrfStoredToken(request, &c)
+		submitted := request.GetHeader(c.HeaderName)
+		if submitted == "" {
+			submitted = request.ctx.PostForm(c.FormFieldName)
+		}
+		if expected == "" || submitted == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(submitted)) != 1 {
+			logger.Logrus().Warningln("csrf token mismatch path:", request.RequestPath())
+			return RespHttpStatusCode(http.StatusForbidden), false, false
+		}
+		return nil, true, true
+	}
+}
+
+// CsrfToken 获取当前请求的CSRF token 不存在时生成并下发 同时写入响应头 可用于渲染模板或JSON响应
+// 需在CsrfInterceptor作用的路由中使用
+func (r *Request) CsrfToken() string {
+	v, ok := r.ctx.Get(ginCtxKeyCsrf)
+	if !ok {
+		logger.Logrus().Warningln("csrf interceptor not enabled path:", r.RequestPath())
+		return ""
+	}
+	state := v.(*csrfState)
+	config := state.config
+	// 会话更换ID后已下发的token失效
+	if state.token != "" && (config.Mode != CsrfSynchronizerToken || r.Session().GetString(sessionCsrfKey) == state.token) {
+		return state.token
+	}
+	if token := csrfStoredToken(r, config); token != "" {
+		state.token = token
+		r.ctx.Header(config.HeaderName, token)
+		return token
+	}
+	state.token = newCsrfToken(config)
+	if config.Mode == CsrfSynchronizerToken {
+		r.Session().Set(sessionCsrfKey, state.token)
+	} else {
+		http.SetCookie(r.ctx.Writer, &http.Cookie{
+			Name:     config.CookieName,
+			Value:    state.token,
+			Path:     config.CookiePath,
+			Domain:   config.CookieDomain,
+			Secure:   config.CookieSecure,
+			HttpOnly: false,
+			SameSite: config.CookieSameSite,
+		})
+	}
+	r.ctx.Header(config.HeaderName, state.token)
+	return state.token
+}
+
+// 是否已存在会话或已下发token 匿名请求不主动创建会话及Cookie
+func csrfIssued(request *Request, config *CsrfConfig) bool {
+	if config.Mode == CsrfSynchronizerToken {
+		if _, err := request.ctx.Cookie(sessionConfig.CookieName); err != nil {
+			return false
+		}
+		return !request.Session().IsNew()
+	}
+	return csrfStoredToken(request, config) != ""
+}
+
+// 获取已下发的token
+func csrfStoredToken(request *Request, config *CsrfConfig) string {
+	if config.Mode == CsrfSynchronizerToken {
+		return request.Session().GetString(sessionCsrfKey)
+	}
+	token, err := request.GetCookie(config.CookieName)
+	if err != nil || token == "" {
+		return ""
+	}
+	if len(config.SecretKey) > 0 && !verifyCsrfToken(config, token) {
+		return ""
+	}
+	return token
+}
+
+func newCsrfToken(config *CsrfConfig) string {
+	random := make([]byte, 32)
+	_, _ = rand.Read(random)
+	token := base64.RawURLEncoding.EncodeToString(random)
+	if config.Mode == CsrfDoubleSubmitCookie && len(config.SecretKey) > 0 {
+		token += "." + csrfSignature(config, token)
+	}
+	return token
+}
+
+func verifyCsrfToken(config *CsrfConfig, token string) bool {
+	index := strings.LastIndex(token, ".")
+	if index == -1 {
+		return false
+	}
+	return hmac.Equal([]byte(token[index+1:]), []byte(csrfSignature(config, token[:index])))
+}
+
+func csrfSignature(config *CsrfConfig, value string) string {
+	mac := hmac.New(sha256.New, config.SecretKey)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// 校验Origin 不存在时校验Referer 均不存在时放行由token校验
+func csrfTrustedOrigin(request *Request, config *CsrfConfig) bool {
+	source := request.GetHeader("Origin")
+	if source == "" {
+		source = request.GetHeader("Referer")
+		if source == "" {
+			return true
+		}
+	}
+	if source == "null" {
+		return false
+	}
+	sourceUrl, err := url.Parse(source)
+	if err != nil || sourceUrl.Host == "" {
+		return false
+	}
+	if strings.EqualFold(sourceUrl.Scheme, csrfRequestScheme(request)) && strings.EqualFold(sourceUrl.Host, request.Host()) {
+		return true
+	}
+	origin := sourceUrl.Scheme + "://" + sourceUrl.Host
+	for _, trusted := range config.TrustedOrigins {
+		if strings.EqualFold(strings.TrimSuffix(trusted, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// 请求协议 TLS终止于反向代理时使用X-Forwarded-Proto 浏览器发起的跨站请求无法设置该请求头
+func csrfRequestScheme(request *Request) string {
+	if request.ctx.Request.TLS != nil {
+		return "https"
+	}
+	if proto := request.GetHeader("X-Forwarded-Proto"); proto != "" {
+		proto, _, _ = strings.Cut(proto, ",")
+		return strings.ToLower(strings.TrimSpace(proto))
+	}
+	return "http"
+}
@@ -0,0 +1,224 @@
+package ginstarter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/acexy/golang-toolkit/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+/**
+请求响应转储
+仅在DebugModule下生效 输出完整的请求及最终响应 包含请求头 请求体 响应头 响应体及耗时
+请求体与响应体超出MaxBodySize的部分将被截断 非文本内容仅输出大小
+*/
+
+// DumpConfig 请求响应转储配置
+type DumpConfig struct {
+	// 请求体及响应体最大输出字节数 默认4096
+	MaxBodySize int
+	// 需要转储的请求路径 以*结尾时按前缀匹配 不设置时转储所有请求
+	IncludePaths []string
+	// 不转储的请求路径 以*结尾时按前缀匹配
+	ExcludePaths []string
+	// 额外需要脱敏的请求头及响应头 默认脱敏Authorization Cookie等认证相关请求头
+	RedactHeaders []string
+	// 额外需要脱敏的JSON 表单及Query字段 忽略大小写 字段名包含其中任意一个即脱敏 如access_token newPassword
+	// 默认脱敏password secret token
+	RedactFields []string
+	// 日志级别 默认Debug
+	Level logrus.Level
+}
+
+var defaultDumpRedactFields = []string{"password", "secret", "token"}
+
+type dumpPolicy struct {
+	config        DumpConfig
+	redactHeaders map[string]bool
+	fieldPattern  *regexp.Regexp
+	jsonPattern   *regexp.Regexp
+	formPattern   *regexp.Regexp
+}
+
+func newDumpPolicy(config *DumpConfig) *dumpPolicy {
+	policy := &dumpPolicy{
+		config:        *config,
+		redactHeaders: make(map[string]bool),
+	}
+	if policy.config.MaxBodySize <= 0 {
+		policy.config.MaxBodySize = 4096
+	}
+	if policy.config.Level == 0 {
+		policy.config.Level = logrus.DebugLevel
+	}
+	for _, header := range append(defaultPanicRedactHeaders, config.RedactHeaders...) {
+		policy.redactHeaders[http.CanonicalHeaderKey(header)] = true
+	}
+	fields := make([]string, 0, len(defaultDumpRedactFields)+len(config.RedactFields))
+	for _, field := range append(defaultDumpRedactFields, config.RedactFields...) {
+		fields = append(fields, regexp.QuoteMeta(field))
+	}
+	names := strings.Join(fields, "|")
+	// 与isAdminSecretField一致 字段名包含脱敏名称即脱敏
+	policy.fieldPattern = regexp.MustCompile(`(?i)` + names)
+	policy.jsonPattern = regexp.MustCompile(`(?i)("(?:[^"\\]|\\.)*?(?:` + names + `)(?:[^"\\]|\\.)*"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`)
+	policy.formPattern = regexp.MustCompile(`(?i)((?:^|&)[^&=]*(?:` + names + `)[^&=]*=)[^&]*`)
+	return policy
+}
+
+// 截取写入的响应体 超出上限的部分仅计数
+type dumpWriter struct {
+	gin.ResponseWriter
+	body  *bytes.Buffer
+	limit int
+}
+
+func (w *dumpWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *dumpWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *dumpWriter) capture(data []byte) {
+	if remain := w.limit - w.body.Len(); remain > 0 {
+		w.body.Write(data[:min(remain, len(data))])
+	}
+}
+
+// 转储中间件 在异常恢复之前执行 记录最终响应
+func dumpHandler(config *DumpConfig) gin.HandlerFunc {
+	policy := newDumpPolicy(config)
+	return func(ctx *gin.Context) {
+		requestPath := ctx.Request.URL.Path
+		if len(policy.config.IncludePaths) > 0 && !accessLogExcluded(policy.config.IncludePaths, requestPath) ||
+			accessLogExcluded(policy.config.ExcludePaths, requestPath) {
+			ctx.Next()
+			return
+		}
+		if !logger.Logrus().IsLevelEnabled(policy.config.Level) {
+			ctx.Next()
+			return
+		}
+
+		var requestBody []byte
+		if ctx.Request.Body != nil && ctx.Request.Body != http.NoBody {
+			requestBody, _ = io.ReadAll(io.LimitReader(ctx.Request.Body, int64(policy.config.MaxBodySize)))
+			ctx.Request.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(requestBody), ctx.Request.Body), ctx.Request.Body}
+		}
+		requestHeader := ctx.Request.Header.Clone()
+
+		writer := &dumpWriter{ResponseWriter: ctx.Writer, body: &bytes.Buffer{}, limit: policy.config.MaxBodySize}
+		ctx.Writer = writer
+		start := time.Now()
+		ctx.Next()
+		latency := time.Since(start)
+		ctx.Writer = writer.ResponseWriter
+
+		builder := strings.Builder{}
+		requestURI := ctx.Request.URL.EscapedPath()
+		if ctx.Request.URL.RawQuery != "" {
+			requestURI += "?" + policy.formPattern.ReplaceAllString(ctx.Request.URL.RawQuery, `${1}******`)
+		}
+		builder.WriteString(fmt.Sprintf("dump %s %s status: %d latency: %s",
+			ctx.Request.Method, requestURI, writer.Status(), latency))
+		// 被BadHttpCodeResolver包裹的响应 输出原始状态码
+		if resolved, ok := ctx.Get(ginCtxKeyResolvedStatus); ok && resolved.(int) != writer.Status() {
+			builder.WriteString(fmt.Sprintf(" resolved-status: %d", resolved.(int)))
+		}
+		if requestId := RequestIdFromContext(ctx.Request.Context()); requestId != "" {
+			builder.WriteString(" request-id: " + requestId)
+		}
+		builder.WriteString(fmt.Sprintf("\n> head: %v", policy.headers(requestHeader)))
+		builder.WriteString("\n> body: " + policy.body(requestBody, max(ctx.Request.ContentLength, int64(len(requestBody))), requestHeader.Get("Content-Type")))
+		builder.WriteString(fmt.Sprintf("\n< head: %v", policy.headers(writer.Header())))
+		builder.WriteString("\n< body: " + policy.body(writer.body.Bytes(), int64(max(writer.Size(), 0)), writer.Header().Get("Content-Type")))
+		logger.Logrus().Log(policy.config.Level, builder.String())
+	}
+}
+
+func (p *dumpPolicy) headers(header http.Header) map[string]string {
+	result := make(map[string]string, len(header))
+	for name, values := range header {
+		if p.redactHeaders[http.CanonicalHeaderKey(name)] {
+			result[name] = "******"
+		} else {
+			result[name] = strings.Join(values, ", ")
+		}
+	}
+	return result
+}
+
+func (p *dumpPolicy) body(data []byte, size int64, contentType string) string {
+	if size == 0 {
+		return ""
+	}
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	var content string
+	switch {
+	case strings.Contains(mediaType, "json"):
+		content = p.redactJSON(data)
+	case mediaType == gin.MIMEPOSTForm:
+		content = p.formPattern.ReplaceAllString(string(data), `${1}******`)
+	case strings.HasPrefix(mediaType, "text/") || strings.Contains(mediaType, "xml") ||
+		mediaType == "application/javascript" || mediaType == "" && utf8.Valid(data):
+		content = string(data)
+	default:
+		if mediaType == "" {
+			mediaType = "binary"
+		}
+		return fmt.Sprintf("<%s %d bytes>", mediaType, size)
+	}
+	if size > int64(len(data)) {
+		content += fmt.Sprintf("...(truncated %d bytes)", size-int64(len(data)))
+	}
+	return content
+}
+
+// 完整的JSON按结构脱敏 被截断的JSON按正则脱敏
+func (p *dumpPolicy) redactJSON(data []byte) string {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return p.jsonPattern.ReplaceAllString(string(data), `${1}"******"`)
+	}
+	redacted, err := json.Marshal(p.redactValue(value))
+	if err != nil {
+		return p.jsonPattern.ReplaceAllString(string(data), `${1}"******"`)
+	}
+	return string(redacted)
+}
+
+func (p *dumpPolicy) redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if p.fieldPattern.MatchString(key) {
+				v[key] = "******"
+			} else {
+				v[key] = p.redactValue(child)
+			}
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = p.redactValue(child)
+		}
+	}
+	return value
+}
@@ -0,0 +1,101 @@
+package ginstarter
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/fs"
+	"net/http"
+
+	"github.com/acexy/golang-toolkit/logger"
+)
+
+// ErrorMapper 错误映射器 将HandlerWrapper返回的错误转换为响应
+// matched=false 时交由下一个映射器处理 所有映射器均未匹配时进入panic流程
+type ErrorMapper func(request *Request, err error) (response Response, matched bool)
+
+// 内置的业务错误映射器 优先于GinConfig.ErrorMappers执行
+func bizErrorMapper(request *Request, err error) (Response, bool) {
+	var bizError *BizError
+	if errors.As(err, &bizError) {
+		bizError.log(request)
+		return bizError.Response(request), true
+	}
+	return nil, false
+}
+
+// 依次执行错误映射器
+func mapHandlerError(request *Request, err error) (Response, bool) {
+	if response, matched := bizErrorMapper(request, err); matched {
+		return response, true
+	}
+	for _, mapper := range ginConfig.ErrorMappers {
+		if response, matched := mapper(request, err); matched {
+			logger.Logrus().Warningln("handler error mapped path:", request.RequestPath(), "error:", err)
+			return response, true
+		}
+	}
+	return nil, false
+}
+
+// ErrorIsMapper 通过errors.Is匹配错误 响应指定的http状态码
+// message 响应的错误信息 不指定则使用状态码对应的默认信息 不会暴露原始错误信息
+func ErrorIsMapper(target error, httpStatusCode int, message ...string) ErrorMapper {
+	return func(request *Request, err error) (Response, bool) {
+		if !errors.Is(err, target) {
+			return nil, false
+		}
+		return httpStatusResponse(request, httpStatusCode, message...), true
+	}
+}
+
+// ErrorAsMapper 通过errors.As匹配错误类型 响应指定的http状态码
+// message 响应的错误信息 不指定则使用状态码对应的默认信息 不会暴露原始错误信息
+func ErrorAsMapper[T error](httpStatusCode int, message ...string) ErrorMapper {
+	return func(request *Request, err error) (Response, bool) {
+		var target T
+		if !errors.As(err, &target) {
+			return nil, false
+		}
+		return httpStatusResponse(request, httpStatusCode, message...), true
+	}
+}
+
+// ErrorMatchMapper 通过自定义条件匹配错误 并由responseFn生成响应
+func ErrorMatchMapper(match func(err error) bool, responseFn func(request *Request, err error) Response) ErrorMapper {
+	return func(request *Request, err error) (Response, bool) {
+		if !match(err) {
+			return nil, false
+		}
+		return responseFn(request, err), true
+	}
+}
+
+// CommonErrorMappers 常用错误映射
+// sql.ErrNoRows -> 404 context.DeadlineExceeded -> 504 fs.ErrPermission -> 403 fs.ErrNotExist -> 404
+func CommonErrorMappers() []ErrorMapper {
+	return []ErrorMapper{
+		ErrorIsMapper(sql.ErrNoRows, http.StatusNotFound),
+		ErrorIsMapper(context.DeadlineExceeded, http.StatusGatewayTimeout),
+		ErrorIsMapper(fs.ErrPermission, http.StatusForbidden),
+		ErrorIsMapper(fs.ErrNotExist, http.StatusNotFound),
+	}
+}
+
+// 生成指定http状态码的响应 启用BadHttpCodeResolver时由其生成响应
+func httpStatusResponse(request *Request, httpStatusCode int, message ...string) Response {
+	var errMsg string
+	if len(message) > 0 {
+		errMsg = message[0]
+	}
+	if !ginConfig.DisableBadHttpCodeResolver {
+		if errMsg == "" {
+			errMsg = localeBadHttpCodeMessage(request.ctx, httpStatusCode)
+		}
+		return ginConfig.BadHttpCodeResolver(httpStatusCode, errMsg)
+	}
+	if errMsg == "" {
+		errMsg = http.StatusText(httpStatusCode)
+	}
+	return RespTextPlain([]byte(errMsg), httpStatusCode)
+}
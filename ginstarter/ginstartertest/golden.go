@@ -0,0 +1,238 @@
+package ginstartertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+/**
+响应快照测试
+将响应的状态码 响应头及响应体记录至testdata/golden目录下的快照文件 再次执行时与快照比较
+时间戳 Trace-Id 请求ID等易变内容将被替换为占位符 使用 go test -update-golden 创建或更新快照
+*/
+
+var updateGolden = flag.Bool("update-golden", false, "update golden files of ginstartertest")
+
+const goldenVolatile = "<volatile>"
+
+// 默认的易变响应头 值将被替换为占位符
+var defaultGoldenVolatileHeaders = []string{"Date", "Set-Cookie", "Traceparent", "X-Csrf-Token"}
+
+// 默认的易变JSON字段
+var defaultGoldenVolatileFields = []string{"status.timestamp"}
+
+type goldenOptions struct {
+	dir             string
+	volatileHeaders []string
+	volatileFields  []string
+	ignoreHeaders   []string
+}
+
+// GoldenOption 快照选项
+type GoldenOption func(options *goldenOptions)
+
+// GoldenDir 快照文件目录 默认testdata/golden
+func GoldenDir(dir string) GoldenOption {
+	return func(options *goldenOptions) {
+		options.dir = dir
+	}
+}
+
+// GoldenVolatileFields 额外的易变JSON字段 以.分隔的路径 *匹配任意数组元素或对象字段 如 data.*.createdAt
+func GoldenVolatileFields(paths ...string) GoldenOption {
+	return func(options *goldenOptions) {
+		options.volatileFields = append(options.volatileFields, paths...)
+	}
+}
+
+// GoldenVolatileHeaders 额外的易变响应头
+func GoldenVolatileHeaders(names ...string) GoldenOption {
+	return func(options *goldenOptions) {
+		options.volatileHeaders = append(options.volatileHeaders, names...)
+	}
+}
+
+// GoldenIgnoreHeaders 不记录至快照的响应头
+func GoldenIgnoreHeaders(names ...string) GoldenOption {
+	return func(options *goldenOptions) {
+		options.ignoreHeaders = append(options.ignoreHeaders, names...)
+	}
+}
+
+// AssertGolden 断言响应与快照一致 指定-update-golden时写入快照 快照不存在时断言失败
+func (r *Response) AssertGolden(name string, opts ...GoldenOption) *Response {
+	r.t.Helper()
+	options := &goldenOptions{
+		dir:             filepath.Join("testdata", "golden"),
+		volatileHeaders: slices.Clone(defaultGoldenVolatileHeaders),
+		volatileFields:  slices.Clone(defaultGoldenVolatileFields),
+	}
+	traceIdHeader := r.harness.config.TraceIdHeaderName
+	if traceIdHeader == "" {
+		traceIdHeader = "Trace-Id"
+	}
+	options.volatileHeaders = append(options.volatileHeaders, traceIdHeader)
+	if requestId := r.harness.config.RequestId; requestId != nil {
+		if requestId.HeaderName == "" {
+			options.volatileHeaders = append(options.volatileHeaders, "X-Request-Id")
+		} else {
+			options.volatileHeaders = append(options.volatileHeaders, requestId.HeaderName)
+		}
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	actual := r.snapshot(options)
+	file := filepath.Join(options.dir, goldenFileName(name))
+	expected, err := os.ReadFile(file)
+	if *updateGolden {
+		if err = os.MkdirAll(filepath.Dir(file), 0o755); err == nil {
+			err = os.WriteFile(file, actual, 0o644)
+		}
+		if err != nil {
+			r.t.Fatalf("write golden file %s failed: %v", file, err)
+		}
+		r.t.Logf("golden file %s updated", file)
+		return r
+	}
+	if os.IsNotExist(err) {
+		r.t.Errorf("golden file %s not found (run with -update-golden to create)", file)
+		return r
+	}
+	if err != nil {
+		r.t.Fatalf("read golden file %s failed: %v", file, err)
+	}
+	if !bytes.Equal(expected, actual) {
+		r.t.Errorf("response does not match golden file %s (run with -update-golden to update)\n%s",
+			file, lineDiff(string(expected), string(actual)))
+	}
+	return r
+}
+
+// 生成快照内容
+func (r *Response) snapshot(options *goldenOptions) []byte {
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("%s %s\n", r.request.Method, r.request.URL.RequestURI()))
+	builder.WriteString(fmt.Sprintf("HTTP %d\n", r.recorder.Code))
+	header := r.recorder.Header()
+	names := make([]string, 0, len(header))
+	for name := range header {
+		if !containsHeader(options.ignoreHeaders, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		value := strings.Join(header.Values(name), ", ")
+		if containsHeader(options.volatileHeaders, name) {
+			value = goldenVolatile
+		}
+		builder.WriteString(name + ": " + value + "\n")
+	}
+	builder.WriteString("\n")
+
+	body := r.Body()
+	var value any
+	if len(body) > 0 && json.Unmarshal(body, &value) == nil {
+		for _, path := range options.volatileFields {
+			value = replaceVolatile(value, strings.Split(path, "."))
+		}
+		buffer := &bytes.Buffer{}
+		encoder := json.NewEncoder(buffer)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(value)
+		builder.Write(buffer.Bytes())
+	} else if len(body) > 0 {
+		builder.Write(body)
+		if body[len(body)-1] != '\n' {
+			builder.WriteString("\n")
+		}
+	}
+	return []byte(builder.String())
+}
+
+func containsHeader(names []string, name string) bool {
+	for _, v := range names {
+		if http.CanonicalHeaderKey(v) == http.CanonicalHeaderKey(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// 将路径匹配的字段替换为占位符 不存在的路径忽略
+func replaceVolatile(value any, path []string) any {
+	if len(path) == 0 {
+		if value == nil {
+			return nil
+		}
+		return goldenVolatile
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if path[0] == "*" || path[0] == key {
+				v[key] = replaceVolatile(child, path[1:])
+			}
+		}
+	case []any:
+		for i, child := range v {
+			if path[0] == "*" || path[0] == fmt.Sprint(i) {
+				v[i] = replaceVolatile(child, path[1:])
+			}
+		}
+	}
+	return value
+}
+
+var goldenNamePattern = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+func goldenFileName(name string) string {
+	return goldenNamePattern.ReplaceAllString(strings.Trim(name, "/"), "_") + ".golden"
+}
+
+// 基于最长公共子序列的逐行差异 -为快照内容 +为实际内容
+func lineDiff(expected, actual string) string {
+	a := strings.Split(expected, "\n")
+	b := strings.Split(actual, "\n")
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	builder := strings.Builder{}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			builder.WriteString("  " + a[i] + "\n")
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			builder.WriteString("- " + a[i] + "\n")
+			i++
+		default:
+			builder.WriteString("+ " + b[j] + "\n")
+			j++
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,257 @@
+package ginstartertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-acexy/starter-gin/ginstarter"
+)
+
+/**
+进程内测试工具
+基于GinConfig构建与Start完全一致的中间件 拦截器及Resolver处理链 但不监听端口
+请求通过httptest直接交由gin引擎处理 响应的Cookie将自动保存并按照Path Secure等属性在后续请求中携带
+请求路径可使用完整的URL 如 https://example.com/path 默认Host为example.com
+由于ginstarter使用全局状态 同一时间仅可存在一个Harness 不支持并行测试
+*/
+
+// Harness 进程内测试服务
+type Harness struct {
+	t       testing.TB
+	config  ginstarter.GinConfig
+	engine  *gin.Engine
+	header  http.Header
+	jar     *cookiejar.Jar
+	closeFn func()
+}
+
+// New 根据配置构建测试服务 测试结束时自动释放
+func New(t testing.TB, config ginstarter.GinConfig) *Harness {
+	t.Helper()
+	engine, closeFn, err := (&ginstarter.GinStarter{Config: config}).BuildEngine()
+	if err != nil {
+		t.Fatalf("build gin engine failed: %v", err)
+	}
+	jar, _ := cookiejar.New(nil)
+	harness := &Harness{
+		t:       t,
+		config:  config,
+		engine:  engine,
+		header:  make(http.Header),
+		jar:     jar,
+		closeFn: closeFn,
+	}
+	t.Cleanup(closeFn)
+	return harness
+}
+
+// Engine 获取原始的gin引擎
+func (h *Harness) Engine() *gin.Engine {
+	return h.engine
+}
+
+// Header 设置所有请求默认携带的请求头
+func (h *Harness) Header(key, value string) *Harness {
+	h.header.Set(key, value)
+	return h
+}
+
+// ClearCookies 清除已保存的Cookie
+func (h *Harness) ClearCookies() *Harness {
+	h.jar, _ = cookiejar.New(nil)
+	return h
+}
+
+func (h *Harness) Get(path string) *RequestBuilder {
+	return h.Request(http.MethodGet, path)
+}
+
+func (h *Harness) Post(path string) *RequestBuilder {
+	return h.Request(http.MethodPost, path)
+}
+
+func (h *Harness) Put(path string) *RequestBuilder {
+	return h.Request(http.MethodPut, path)
+}
+
+func (h *Harness) Patch(path string) *RequestBuilder {
+	return h.Request(http.MethodPatch, path)
+}
+
+func (h *Harness) Delete(path string) *RequestBuilder {
+	return h.Request(http.MethodDelete, path)
+}
+
+// Request 创建指定方法的请求
+func (h *Harness) Request(method, path string) *RequestBuilder {
+	return &RequestBuilder{
+		harness: h,
+		method:  method,
+		path:    path,
+		query:   make(url.Values),
+		header:  h.header.Clone(),
+	}
+}
+
+// MultipartFile multipart请求中的文件
+type MultipartFile struct {
+	FieldName string
+	FileName  string
+	Content   []byte
+}
+
+// RequestBuilder 请求构造器
+type RequestBuilder struct {
+	harness     *Harness
+	method      string
+	path        string
+	query       url.Values
+	header      http.Header
+	cookies     []*http.Cookie
+	body        []byte
+	contentType string
+	remoteAddr  string
+}
+
+func (r *RequestBuilder) Query(key, value string) *RequestBuilder {
+	r.query.Add(key, value)
+	return r
+}
+
+func (r *RequestBuilder) Header(key, value string) *RequestBuilder {
+	r.header.Set(key, value)
+	return r
+}
+
+func (r *RequestBuilder) Cookie(cookie *http.Cookie) *RequestBuilder {
+	r.cookies = append(r.cookies, cookie)
+	return r
+}
+
+// BasicAuth 设置Basic认证请求头
+func (r *RequestBuilder) BasicAuth(username, password string) *RequestBuilder {
+	request := http.Request{Header: make(http.Header)}
+	request.SetBasicAuth(username, password)
+	r.header.Set("Authorization", request.Header.Get("Authorization"))
+	return r
+}
+
+// BearerToken 设置Bearer认证请求头
+func (r *RequestBuilder) BearerToken(token string) *RequestBuilder {
+	r.header.Set("Authorization", "Bearer "+token)
+	return r
+}
+
+// RemoteAddr 设置请求的远端地址 默认192.0.2.1:1234
+func (r *RequestBuilder) RemoteAddr(remoteAddr string) *RequestBuilder {
+	r.remoteAddr = remoteAddr
+	return r
+}
+
+// JSON 以JSON格式提交请求体 string及[]byte将原样提交
+func (r *RequestBuilder) JSON(body any) *RequestBuilder {
+	switch v := body.(type) {
+	case string:
+		r.body = []byte(v)
+	case []byte:
+		r.body = v
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			r.harness.t.Helper()
+			r.harness.t.Fatalf("marshal json body failed: %v", err)
+		}
+		r.body = data
+	}
+	r.contentType = gin.MIMEJSON
+	return r
+}
+
+// Form 以表单格式提交请求体
+func (r *RequestBuilder) Form(values map[string]string) *RequestBuilder {
+	form := make(url.Values, len(values))
+	for k, v := range values {
+		form.Set(k, v)
+	}
+	r.body = []byte(form.Encode())
+	r.contentType = gin.MIMEPOSTForm
+	return r
+}
+
+// Multipart 以multipart格式提交表单及文件
+func (r *RequestBuilder) Multipart(values map[string]string, files ...MultipartFile) *RequestBuilder {
+	buffer := &bytes.Buffer{}
+	writer := multipart.NewWriter(buffer)
+	for k, v := range values {
+		_ = writer.WriteField(k, v)
+	}
+	for _, file := range files {
+		part, _ := writer.CreateFormFile(file.FieldName, file.FileName)
+		_, _ = part.Write(file.Content)
+	}
+	_ = writer.Close()
+	r.body = buffer.Bytes()
+	r.contentType = writer.FormDataContentType()
+	return r
+}
+
+// Body 提交原始请求体
+func (r *RequestBuilder) Body(contentType string, body []byte) *RequestBuilder {
+	r.body = body
+	r.contentType = contentType
+	return r
+}
+
+// Do 执行请求
+func (r *RequestBuilder) Do() *Response {
+	r.harness.t.Helper()
+	target := r.path
+	if len(r.query) > 0 {
+		if strings.Contains(target, "?") {
+			target += "&" + r.query.Encode()
+		} else {
+			target += "?" + r.query.Encode()
+		}
+	}
+	var body io.Reader
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
+	request := httptest.NewRequest(r.method, target, body)
+	request.Header = r.header
+	if r.contentType != "" && request.Header.Get("Content-Type") == "" {
+		request.Header.Set("Content-Type", r.contentType)
+	}
+	if r.remoteAddr != "" {
+		request.RemoteAddr = r.remoteAddr
+	}
+	jarURL := cookieURL(request)
+	for _, cookie := range r.harness.jar.Cookies(jarURL) {
+		request.AddCookie(cookie)
+	}
+	for _, cookie := range r.cookies {
+		request.AddCookie(cookie)
+	}
+	recorder := httptest.NewRecorder()
+	r.harness.engine.ServeHTTP(recorder, request)
+	r.harness.jar.SetCookies(jarURL, recorder.Result().Cookies())
+	return &Response{t: r.harness.t, harness: r.harness, request: request, recorder: recorder}
+}
+
+// Cookie按照请求的协议 Host及路径匹配
+func cookieURL(request *http.Request) *url.URL {
+	scheme := "http"
+	if request.TLS != nil {
+		scheme = "https"
+	}
+	return &url.URL{Scheme: scheme, Host: request.Host, Path: request.URL.Path}
+}
@@ -0,0 +1,204 @@
+package ginstartertest
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+)
+
+/**
+OpenAPI契约
+根据已注册的路由表生成OpenAPI 3.0文档骨架 默认响应为任意Data的RestRespStruct
+可通过SetResponse为各接口声明具体的响应Schema 并写入文件作为契约
+*/
+
+// OpenAPI OpenAPI 3.0文档
+type OpenAPI struct {
+	OpenAPI string                           `json:"openapi"`
+	Info    OpenAPIInfo                      `json:"info"`
+	Paths   map[string]map[string]*Operation `json:"paths"`
+}
+
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Operation 接口定义 key为响应状态码或default
+type Operation struct {
+	Summary   string                      `json:"summary,omitempty"`
+	Responses map[string]*OpenAPIResponse `json:"responses"`
+}
+
+type OpenAPIResponse struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// GenerateOpenAPI 根据已注册的路由表生成文档 需在构建Harness之后调用
+func GenerateOpenAPI(title, version string) *OpenAPI {
+	doc := &OpenAPI{
+		OpenAPI: "3.0.3",
+		Info:    OpenAPIInfo{Title: title, Version: version},
+		Paths:   make(map[string]map[string]*Operation),
+	}
+	for _, route := range ginstarter.Routes() {
+		doc.operation(route.Method, route.Path).Summary = route.String()
+		doc.SetResponse(route.Method, route.Path, "default", RestSchema(nil))
+	}
+	return doc
+}
+
+// LoadOpenAPI 从JSON文件加载文档
+func LoadOpenAPI(file string) (*OpenAPI, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	doc := &OpenAPI{}
+	if err = json.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// WriteFile 以JSON格式写入文件
+func (d *OpenAPI) WriteFile(file string) error {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0o644)
+}
+
+// SetResponse 声明接口的JSON响应Schema path可使用gin格式(:id)或OpenAPI格式({id}) status为http状态码或default
+func (d *OpenAPI) SetResponse(method, path, status string, schema *Schema) *OpenAPI {
+	operation := d.operation(method, path)
+	operation.Responses[status] = &OpenAPIResponse{
+		Description: statusDescription(status),
+		Content:     map[string]*MediaType{"application/json": {Schema: schema}},
+	}
+	return d
+}
+
+func (d *OpenAPI) operation(method, path string) *Operation {
+	path = openAPIPath(path)
+	if d.Paths[path] == nil {
+		d.Paths[path] = make(map[string]*Operation)
+	}
+	method = strings.ToLower(method)
+	operation := d.Paths[path][method]
+	if operation == nil {
+		operation = &Operation{Responses: make(map[string]*OpenAPIResponse)}
+		d.Paths[path][method] = operation
+	}
+	return operation
+}
+
+// 查找与请求路径匹配的接口 优先匹配静态路径段
+func (d *OpenAPI) lookup(method, requestPath string) (*Operation, string) {
+	method = strings.ToLower(method)
+	if operation := d.Paths[requestPath][method]; operation != nil {
+		return operation, requestPath
+	}
+	var matched *Operation
+	var matchedPath string
+	matchedParams := -1
+	segments := strings.Split(strings.Trim(requestPath, "/"), "/")
+	for template, operations := range d.Paths {
+		operation := operations[method]
+		if operation == nil {
+			continue
+		}
+		if params, ok := matchOpenAPIPath(strings.Split(strings.Trim(template, "/"), "/"), segments); ok {
+			if matched == nil || params < matchedParams {
+				matched, matchedPath, matchedParams = operation, template, params
+			}
+		}
+	}
+	return matched, matchedPath
+}
+
+func matchOpenAPIPath(template, segments []string) (int, bool) {
+	params := 0
+	for i, part := range template {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			params++
+			// gin的*通配参数匹配剩余所有路径段
+			if i == len(template)-1 && strings.HasSuffix(part, "...}") {
+				return params, len(segments) >= i
+			}
+			if i >= len(segments) || segments[i] == "" {
+				return 0, false
+			}
+			continue
+		}
+		if i >= len(segments) || segments[i] != part {
+			return 0, false
+		}
+	}
+	return params, len(template) == len(segments)
+}
+
+// 将gin的路径参数转换为OpenAPI格式 *通配参数以{name...}表示
+func openAPIPath(path string) string {
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		if name, ok := strings.CutPrefix(part, ":"); ok {
+			parts[i] = "{" + name + "}"
+		} else if name, ok = strings.CutPrefix(part, "*"); ok {
+			parts[i] = "{" + name + "...}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func statusDescription(status string) string {
+	if code, err := strconv.Atoi(status); err == nil {
+		return http.StatusText(code)
+	}
+	return "default response"
+}
+
+// AssertOpenAPI 断言响应符合文档中对应接口及状态码声明的Schema
+func (r *Response) AssertOpenAPI(doc *OpenAPI) *Response {
+	r.t.Helper()
+	operation, path := doc.lookup(r.request.Method, r.request.URL.Path)
+	if operation == nil {
+		r.t.Errorf("operation %s %s is not declared in openapi", r.request.Method, r.request.URL.Path)
+		return r
+	}
+	response := operation.Responses[strconv.Itoa(r.recorder.Code)]
+	if response == nil {
+		response = operation.Responses["default"]
+	}
+	if response == nil {
+		r.t.Errorf("response %d of %s %s is not declared in openapi", r.recorder.Code, r.request.Method, path)
+		return r
+	}
+	if len(response.Content) == 0 {
+		return r
+	}
+	mediaType := strings.TrimSpace(strings.Split(r.recorder.Header().Get("Content-Type"), ";")[0])
+	content := response.Content[mediaType]
+	if content == nil {
+		r.t.Errorf("content type %q of %s %s is not declared in openapi", mediaType, r.request.Method, path)
+		return r
+	}
+	if content.Schema != nil {
+		r.AssertSchema(content.Schema)
+	}
+	return r
+}
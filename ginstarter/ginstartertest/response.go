@@ -0,0 +1,177 @@
+package ginstartertest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+)
+
+// Response 测试请求的响应
+type Response struct {
+	t        testing.TB
+	harness  *Harness
+	request  *http.Request
+	recorder *httptest.ResponseRecorder
+	rest     *restResp
+}
+
+// 用于解码的Rest结构 保留原始Data以便按需解码
+type restResp struct {
+	Status *ginstarter.RestRespStatusStruct `json:"status"`
+	Data   json.RawMessage                  `json:"data"`
+}
+
+// Recorder 获取原始的响应记录
+func (r *Response) Recorder() *httptest.ResponseRecorder {
+	return r.recorder
+}
+
+// Request 获取发送的请求
+func (r *Response) Request() *http.Request {
+	return r.request
+}
+
+// HttpStatus 获取http响应码
+func (r *Response) HttpStatus() int {
+	return r.recorder.Code
+}
+
+func (r *Response) Header() http.Header {
+	return r.recorder.Header()
+}
+
+func (r *Response) Cookies() []*http.Cookie {
+	return r.recorder.Result().Cookies()
+}
+
+func (r *Response) Body() []byte {
+	return r.recorder.Body.Bytes()
+}
+
+func (r *Response) String() string {
+	return r.recorder.Body.String()
+}
+
+// DecodeJSON 将响应体解码至v
+func (r *Response) DecodeJSON(v any) *Response {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Body(), v); err != nil {
+		r.t.Fatalf("decode response body failed: %v body: %s", err, r.String())
+	}
+	return r
+}
+
+// Rest 将响应体解码为RestRespStruct Data保持为json.RawMessage
+func (r *Response) Rest() *ginstarter.RestRespStruct {
+	r.t.Helper()
+	rest := r.decodeRest()
+	result := &ginstarter.RestRespStruct{Status: rest.Status}
+	if len(rest.Data) > 0 && string(rest.Data) != "null" {
+		result.Data = rest.Data
+	}
+	return result
+}
+
+// DecodeData 将Rest响应中的Data解码至v
+func (r *Response) DecodeData(v any) *Response {
+	r.t.Helper()
+	rest := r.decodeRest()
+	if err := json.Unmarshal(rest.Data, v); err != nil {
+		r.t.Fatalf("decode response data failed: %v data: %s", err, string(rest.Data))
+	}
+	return r
+}
+
+func (r *Response) decodeRest() *restResp {
+	r.t.Helper()
+	if r.rest == nil {
+		rest := &restResp{}
+		if err := json.Unmarshal(r.Body(), rest); err != nil || rest.Status == nil {
+			r.t.Fatalf("response is not a rest struct: %s", r.String())
+		}
+		r.rest = rest
+	}
+	return r.rest
+}
+
+// AssertHttpStatus 断言http响应码
+func (r *Response) AssertHttpStatus(expected int) *Response {
+	r.t.Helper()
+	if r.recorder.Code != expected {
+		r.t.Errorf("http status: expected %d actual %d body: %s", expected, r.recorder.Code, r.String())
+	}
+	return r
+}
+
+// AssertHeader 断言响应头
+func (r *Response) AssertHeader(key, expected string) *Response {
+	r.t.Helper()
+	if actual := r.recorder.Header().Get(key); actual != expected {
+		r.t.Errorf("header %s: expected %q actual %q", key, expected, actual)
+	}
+	return r
+}
+
+// AssertStatusCode 断言Rest响应中的StatusCode
+func (r *Response) AssertStatusCode(expected ginstarter.StatusCode) *Response {
+	r.t.Helper()
+	if actual := r.decodeRest().Status.StatusCode; actual != expected {
+		r.t.Errorf("rest status code: expected %d actual %d body: %s", expected, actual, r.String())
+	}
+	return r
+}
+
+// AssertSuccess 断言Rest响应为成功状态
+func (r *Response) AssertSuccess() *Response {
+	r.t.Helper()
+	status := r.decodeRest().Status
+	if status.StatusCode != ginstarter.StatusCodeSuccess || status.BizErrorCode != nil {
+		r.t.Errorf("rest response is not success body: %s", r.String())
+	}
+	return r
+}
+
+// AssertBizErrorCode 断言Rest响应中的BizErrorCode
+func (r *Response) AssertBizErrorCode(expected ginstarter.BizErrorCode) *Response {
+	r.t.Helper()
+	actual := r.decodeRest().Status.BizErrorCode
+	if actual == nil || *actual != expected {
+		r.t.Errorf("rest biz error code: expected %d actual %v body: %s", expected, actual, r.String())
+	}
+	return r
+}
+
+// AssertData 断言Rest响应中的Data 以JSON语义比较 expected为string或[]byte时视为JSON文本
+func (r *Response) AssertData(expected any) *Response {
+	r.t.Helper()
+	var expectedJSON []byte
+	switch v := expected.(type) {
+	case string:
+		expectedJSON = []byte(v)
+	case []byte:
+		expectedJSON = v
+	default:
+		data, err := json.Marshal(expected)
+		if err != nil {
+			r.t.Fatalf("marshal expected data failed: %v", err)
+		}
+		expectedJSON = data
+	}
+	var expectedValue, actualValue any
+	if err := json.Unmarshal(expectedJSON, &expectedValue); err != nil {
+		r.t.Fatalf("expected data is not valid json: %v", err)
+	}
+	actualData := r.decodeRest().Data
+	if len(actualData) == 0 {
+		actualData = []byte("null")
+	}
+	_ = json.Unmarshal(actualData, &actualValue)
+	if !reflect.DeepEqual(expectedValue, actualValue) {
+		r.t.Errorf("rest data: expected %s actual %s", string(expectedJSON), string(actualData))
+	}
+	return r
+}
@@ -0,0 +1,264 @@
+package ginstartertest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+)
+
+/**
+OpenAPI Schema
+根据Go类型及json tag生成OpenAPI 3.0 Schema 并校验响应内容是否符合Schema
+未声明的对象字段默认允许 设置AdditionalProperties为false时视为错误
+*/
+
+// Schema OpenAPI 3.0 Schema对象
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// SchemaOf 根据Go类型生成Schema 指针类型为nullable omitempty字段为非必需
+func SchemaOf(v any) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+	return schemaOfType(reflect.TypeOf(v), make(map[reflect.Type]bool))
+}
+
+// RestSchema 生成以RestRespStruct包裹的Schema data为nil时Data为任意类型
+func RestSchema(data any) *Schema {
+	schema := SchemaOf(ginstarter.RestRespStruct{})
+	dataSchema := SchemaOf(data)
+	dataSchema.Nullable = true
+	schema.Properties["data"] = dataSchema
+	return schema
+}
+
+func schemaOfType(t reflect.Type, visiting map[reflect.Type]bool) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	case t.Implements(reflect.TypeOf((*json.Marshaler)(nil)).Elem()) && t.Kind() != reflect.Pointer:
+		// 自定义序列化的类型无法推断
+		return &Schema{}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := schemaOfType(t.Elem(), visiting)
+		schema.Nullable = true
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		schema := &Schema{Type: "array", Items: schemaOfType(t.Elem(), visiting)}
+		if t.Kind() == reflect.Slice {
+			schema.Nullable = true
+		}
+		return schema
+	case reflect.Map:
+		return &Schema{Type: "object", Nullable: true, AdditionalProperties: schemaOfType(t.Elem(), visiting)}
+	case reflect.Struct:
+		if visiting[t] {
+			return &Schema{Type: "object"}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		collectProperties(t, schema, visiting)
+		return schema
+	default:
+		return &Schema{}
+	}
+}
+
+func collectProperties(t reflect.Type, schema *Schema, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		fieldType := field.Type
+		if field.Anonymous && name == "" {
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				collectProperties(fieldType, schema, visiting)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = schemaOfType(field.Type, visiting)
+		if !strings.Contains(options, "omitempty") && !strings.Contains(options, "omitzero") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	slices.Sort(schema.Required)
+}
+
+// Validate 校验JSON解码后的值是否符合Schema 返回所有不符合项
+func (s *Schema) Validate(value any) []string {
+	var errs []string
+	s.validate(value, "$", &errs)
+	return errs
+}
+
+func (s *Schema) validate(value any, path string, errs *[]string) {
+	if value == nil {
+		if s.Type != "" && !s.Nullable {
+			*errs = append(*errs, fmt.Sprintf("%s: null is not allowed", path))
+		}
+		return
+	}
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return reflect.DeepEqual(normalizeJSON(e), value) }) {
+		*errs = append(*errs, fmt.Sprintf("%s: %v is not one of %v", path, value, s.Enum))
+	}
+	switch s.Type {
+	case "":
+		return
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			*errs = append(*errs, fmt.Sprintf("%s: expected boolean got %s", path, jsonType(value)))
+		}
+	case "integer":
+		if n, ok := value.(float64); !ok || n != math.Trunc(n) {
+			*errs = append(*errs, fmt.Sprintf("%s: expected integer got %s", path, jsonType(value)))
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			*errs = append(*errs, fmt.Sprintf("%s: expected number got %s", path, jsonType(value)))
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			*errs = append(*errs, fmt.Sprintf("%s: expected string got %s", path, jsonType(value)))
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			*errs = append(*errs, fmt.Sprintf("%s: expected array got %s", path, jsonType(value)))
+			return
+		}
+		if s.Items != nil {
+			for i, item := range items {
+				s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			*errs = append(*errs, fmt.Sprintf("%s: expected object got %s", path, jsonType(value)))
+			return
+		}
+		for _, name := range s.Required {
+			if _, exists := object[name]; !exists {
+				*errs = append(*errs, fmt.Sprintf("%s.%s: required property is missing", path, name))
+			}
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			if property, exists := s.Properties[key]; exists {
+				property.validate(object[key], path+"."+key, errs)
+				continue
+			}
+			switch additional := s.AdditionalProperties.(type) {
+			case bool:
+				if !additional {
+					*errs = append(*errs, fmt.Sprintf("%s.%s: property is not allowed", path, key))
+				}
+			case *Schema:
+				additional.validate(object[key], path+"."+key, errs)
+			case map[string]any:
+				// 从文件加载的Schema
+				if data, err := json.Marshal(additional); err == nil {
+					schema := &Schema{}
+					if json.Unmarshal(data, schema) == nil {
+						schema.validate(object[key], path+"."+key, errs)
+					}
+				}
+			}
+		}
+	}
+}
+
+func normalizeJSON(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var result any
+	_ = json.Unmarshal(data, &result)
+	return result
+}
+
+func jsonType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// AssertSchema 断言响应体符合Schema
+func (r *Response) AssertSchema(schema *Schema) *Response {
+	r.t.Helper()
+	var value any
+	if err := json.Unmarshal(r.Body(), &value); err != nil {
+		r.t.Fatalf("response is not json: %v body: %s", err, r.String())
+	}
+	if errs := schema.Validate(value); len(errs) > 0 {
+		r.t.Errorf("response does not match schema:\n%s\nbody: %s", strings.Join(errs, "\n"), r.String())
+	}
+	return r
+}
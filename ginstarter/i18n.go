@@ -0,0 +1,309 @@
+package ginstarter
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/acexy/golang-toolkit/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	zhTranslations "github.com/go-playground/validator/v10/translations/zh"
+)
+
+/**
+国际化支持
+语言解析顺序: Query参数 > Cookie > Accept-Language > 默认语言
+*/
+
+const (
+	LocaleEn = "en"
+	LocaleZh = "zh"
+)
+
+var universalTranslator *ut.UniversalTranslator
+var i18nConfig *I18nConfig
+
+// 业务错误信息模板 locale -> BizErrorCode -> BizErrorMessage 不经过翻译器格式化 避免参数不足时越界
+var bizErrorTemplates map[string]map[BizErrorCode]BizErrorMessage
+
+// I18nConfig 国际化配置
+type I18nConfig struct {
+	// 默认语言 当请求中未能解析出支持的语言时使用 默认 en
+	DefaultLocale string
+	// 启用的语言 目前内置 en zh 两种语言 默认全部启用
+	Locales []string
+
+	// 从Query参数中获取语言的参数名 例如 lang 为空则不从Query中获取
+	QueryParamName string
+	// 从Cookie中获取语言的Cookie名 为空则不从Cookie中获取
+	CookieName string
+	// 禁用从Accept-Language请求头中获取语言
+	DisableAcceptLanguage bool
+
+	// 自定义状态信息 locale -> StatusCode -> StatusMessage 将覆盖内置的状态信息
+	StatusMessages map[string]map[StatusCode]StatusMessage
+	// 业务错误信息 locale -> BizErrorCode -> BizErrorMessage 信息中可以使用{0} {1}作为参数占位符
+	BizErrorMessages map[string]map[BizErrorCode]BizErrorMessage
+}
+
+type builtinLocale struct {
+	translator         func() locales.Translator
+	registerValidators func(v *validator.Validate, trans ut.Translator) error
+	statusMessages     map[StatusCode]StatusMessage
+}
+
+var builtinLocales = map[string]builtinLocale{
+	LocaleEn: {
+		translator:         en.New,
+		registerValidators: enTranslations.RegisterDefaultTranslations,
+		statusMessages:     statusCodeWithMessageEn,
+	},
+	LocaleZh: {
+		translator:         zh.New,
+		registerValidators: zhTranslations.RegisterDefaultTranslations,
+		statusMessages:     statusCodeWithMessageZh,
+	},
+}
+
+var statusCodeWithMessageEn = func() map[StatusCode]StatusMessage {
+	messages := make(map[StatusCode]StatusMessage, len(statusCodeWithMessage)+1)
+	messages[StatusCodeSuccess] = statusMessageSuccess
+	for k, v := range statusCodeWithMessage {
+		messages[k] = v
+	}
+	return messages
+}()
+
+var statusCodeWithMessageZh = map[StatusCode]StatusMessage{
+	StatusCodeSuccess:              "请求成功",
+	StatusCodeServiceUnavailable:   "服务不可用",
+	StatusCodeExceededLimit:        "请求超出限制",
+	StatusCodeTimeout:              "服务超时",
+	StatusCodeException:            "系统错误",
+	StatusCodeNotFound:             "请求资源不存在",
+	StatusCodeForbidden:            "请求被禁止",
+	StatusCodeMethodNotAllowed:     "请求方法不被允许",
+	StatusCodeMediaTypeNotAllowed:  "请求媒体类型不被允许",
+	StatusCodeUploadLimitExceeded:  "上传文件大小超出限制",
+	StatusCodeUnauthorized:         "未授权的请求",
+	StatusCodeBadRequestParameters: "请求参数错误",
+}
+
+// 初始化国际化组件 未配置时不启用
+func initI18n(config *I18nConfig) {
+	universalTranslator = nil
+	bizErrorTemplates = nil
+	i18nConfig = config
+	if config == nil {
+		return
+	}
+	v, _ := binding.Validator.Engine().(*validator.Validate)
+	if v != nil {
+		// 翻译的验证错误信息中使用tag声明的字段名 可在InitFunc中重新注册
+		v.RegisterTagNameFunc(validatorFieldName)
+	}
+	if config.DefaultLocale == "" {
+		config.DefaultLocale = LocaleEn
+	}
+	if len(config.Locales) == 0 {
+		config.Locales = []string{LocaleEn, LocaleZh}
+	}
+	fallback, ok := builtinLocales[config.DefaultLocale]
+	if !ok {
+		logger.Logrus().Warningln("unsupported default locale:", config.DefaultLocale, "use", LocaleEn)
+		config.DefaultLocale = LocaleEn
+		fallback = builtinLocales[LocaleEn]
+	}
+	fallbackTranslator := fallback.translator()
+	universalTranslator = ut.New(fallbackTranslator, fallbackTranslator)
+	bizErrorTemplates = make(map[string]map[BizErrorCode]BizErrorMessage)
+	for _, locale := range config.Locales {
+		builtin, ok := builtinLocales[locale]
+		if !ok {
+			logger.Logrus().Warningln("unsupported locale:", locale)
+			continue
+		}
+		if locale != config.DefaultLocale {
+			_ = universalTranslator.AddTranslator(builtin.translator(), true)
+		}
+		trans, _ := universalTranslator.GetTranslator(locale)
+		if v != nil {
+			if err := builtin.registerValidators(v, trans); err != nil {
+				logger.Logrus().Warningln("register validator translations error locale:", locale, err)
+			}
+			registerValidatorTranslations(v, locale, trans)
+		}
+		for code, message := range builtin.statusMessages {
+			_ = trans.Add(code, string(message), true)
+		}
+		for code, message := range config.StatusMessages[locale] {
+			_ = trans.Add(code, string(message), true)
+		}
+		if messages := config.BizErrorMessages[locale]; len(messages) > 0 {
+			bizErrorTemplates[trans.Locale()] = messages
+		}
+	}
+}
+
+// 获取当前请求的翻译器 未启用国际化时返回nil
+func requestTranslator(ctx *gin.Context) ut.Translator {
+	if universalTranslator == nil {
+		return nil
+	}
+	trans, _ := universalTranslator.GetTranslator(requestLocale(ctx))
+	return trans
+}
+
+// 解析当前请求的语言
+func requestLocale(ctx *gin.Context) string {
+	if i18nConfig == nil {
+		return ""
+	}
+	if v, ok := ctx.Get(ginCtxKeyLocale); ok {
+		return v.(string)
+	}
+	locale := resolveLocale(ctx)
+	ctx.Set(ginCtxKeyLocale, locale)
+	return locale
+}
+
+func resolveLocale(ctx *gin.Context) string {
+	var candidates []string
+	if i18nConfig.QueryParamName != "" {
+		if v, ok := ctx.GetQuery(i18nConfig.QueryParamName); ok {
+			candidates = append(candidates, v)
+		}
+	}
+	if i18nConfig.CookieName != "" {
+		if v, err := ctx.Cookie(i18nConfig.CookieName); err == nil {
+			candidates = append(candidates, v)
+		}
+	}
+	if !i18nConfig.DisableAcceptLanguage {
+		candidates = append(candidates, parseAcceptLanguage(ctx.GetHeader("Accept-Language"))...)
+	}
+	for _, candidate := range candidates {
+		if locale, ok := matchLocale(candidate); ok {
+			return locale
+		}
+	}
+	return i18nConfig.DefaultLocale
+}
+
+// 匹配已启用的语言 zh-CN 将依次尝试 zh_cn zh
+func matchLocale(tag string) (string, bool) {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "-", "_"))
+	if tag == "" {
+		return "", false
+	}
+	if trans, ok := universalTranslator.GetTranslator(tag); ok {
+		return trans.Locale(), true
+	}
+	if index := strings.Index(tag, "_"); index > 0 {
+		if trans, ok := universalTranslator.GetTranslator(tag[:index]); ok {
+			return trans.Locale(), true
+		}
+	}
+	return "", false
+}
+
+// 解析Accept-Language 按权重排序 zh-CN,zh;q=0.9,en;q=0.8
+func parseAcceptLanguage(header string) []string {
+	if header == "" {
+		return nil
+	}
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	parts := strings.Split(header, ",")
+	tags := make([]weighted, 0, len(parts))
+	for _, part := range parts {
+		segments := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.TrimSpace(segments[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range segments[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+	result := make([]string, len(tags))
+	for i, v := range tags {
+		result[i] = v.tag
+	}
+	return result
+}
+
+// 获取指定请求语言的状态信息 未启用国际化或无对应翻译时返回默认状态信息
+func localeStatusMessage(ctx *gin.Context, statusCode StatusCode) StatusMessage {
+	if trans := requestTranslator(ctx); trans != nil {
+		if message, err := trans.T(statusCode); err == nil {
+			return StatusMessage(message)
+		}
+	}
+	if statusCode == StatusCodeSuccess {
+		return statusMessageSuccess
+	}
+	return GetStatusMessage(statusCode)
+}
+
+// 获取异常http响应码对应的请求语言状态信息 未启用国际化时返回空 由BadHttpCodeResolver使用默认信息
+func localeBadHttpCodeMessage(ctx *gin.Context, httpStatusCode int) string {
+	if universalTranslator == nil {
+		return ""
+	}
+	statusCode, ok := httpCodeWithStatus[httpStatusCode]
+	if !ok {
+		statusCode = StatusCodeException
+	}
+	return string(localeStatusMessage(ctx, statusCode))
+}
+
+// 获取指定请求语言的业务错误信息模板 不存在时使用默认语言的模板
+func localeBizErrorTemplate(ctx *gin.Context, bizErrorCode BizErrorCode) (BizErrorMessage, bool) {
+	trans := requestTranslator(ctx)
+	if trans == nil {
+		return "", false
+	}
+	if message, ok := bizErrorTemplates[trans.Locale()][bizErrorCode]; ok {
+		return message, true
+	}
+	message, ok := bizErrorTemplates[universalTranslator.GetFallback().Locale()][bizErrorCode]
+	return message, ok
+}
+
+// 获取指定请求语言的业务错误信息 无对应翻译时返回空
+func localeBizErrorMessage(ctx *gin.Context, bizErrorCode BizErrorCode, params ...string) (BizErrorMessage, bool) {
+	message, ok := localeBizErrorTemplate(ctx, bizErrorCode)
+	if !ok {
+		return "", false
+	}
+	return formatBizErrorMessage(message, params...), true
+}
+
+// RespRestLocaleBizError 响应标准格式的Rest业务错误 错误信息将根据请求语言从I18nConfig.BizErrorMessages中获取
+func RespRestLocaleBizError(request *Request, bizErrorCode BizErrorCode, params ...string) Response {
+	dataRest := NewRestBizError(bizErrorCode, request.BizErrorMessage(bizErrorCode, params...))
+	dataRest.Status.StatusMessage = request.StatusMessage(StatusCodeSuccess)
+	return RespRestRaw(dataRest)
+}
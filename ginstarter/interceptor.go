@@ -74,6 +74,12 @@ type internalPanic struct {
 	rawError   error
 }
 
+// 在handler协程中捕获后重新抛出的panic 保留handler协程的调用栈
+type recoveredPanic struct {
+	value any
+	stack string
+}
+
 // PreInterceptor 前置拦截器
 // 任意一个拦截器返回continueHandler=false都将阻止handler执行
 type PreInterceptor func(request *Request) (response Response, continuePreInterceptor bool, continueHandler bool)
@@ -85,7 +91,7 @@ type PanicResolver func(err error) string
 type BadHttpCodeResolver func(httpStatusCode int, errMsg string) Response
 
 func init() {
-	httpCodeWithStatus = make(map[int]StatusCode, 7)
+	httpCodeWithStatus = make(map[int]StatusCode, 10)
 	httpCodeWithStatus[http.StatusBadRequest] = StatusCodeBadRequestParameters
 	httpCodeWithStatus[http.StatusForbidden] = StatusCodeForbidden
 	httpCodeWithStatus[http.StatusNotFound] = StatusCodeNotFound
@@ -93,6 +99,9 @@ func init() {
 	httpCodeWithStatus[http.StatusUnsupportedMediaType] = StatusCodeMediaTypeNotAllowed
 	httpCodeWithStatus[http.StatusRequestEntityTooLarge] = StatusCodeUploadLimitExceeded
 	httpCodeWithStatus[http.StatusUnauthorized] = StatusCodeUnauthorized
+	httpCodeWithStatus[http.StatusTooManyRequests] = StatusCodeExceededLimit
+	httpCodeWithStatus[http.StatusServiceUnavailable] = StatusCodeServiceUnavailable
+	httpCodeWithStatus[http.StatusGatewayTimeout] = StatusCodeTimeout
 }
 
 func isIgnoreHttpStatusCode(httpCode int) bool {
@@ -113,7 +122,8 @@ func isIgnoreHttpStatusCode(httpCode int) bool {
 	return false
 }
 
-func panicToError(panicError any) (statusCode int, err error, internalError bool) {
+// capturedStack 不为空时使用已捕获的调用栈
+func panicToError(ctx *gin.Context, panicError any, capturedStack string) (statusCode int, err error, internalError bool, stack string) {
 	switch t := panicError.(type) {
 	case string:
 		err = errors.New(t)
@@ -126,7 +136,7 @@ func panicToError(panicError any) (statusCode int, err error, internalError bool
 			statusCode = v.statusCode
 			if validationErrs, ok := rawError.(validator.ValidationErrors); ok {
 				internalError = true
-				err = errors.New(friendlyValidatorMessage(validationErrs))
+				err = errors.New(friendlyValidatorMessage(validationErrs, requestTranslator(ctx)))
 			} else if jsonErr, ok := rawError.(*json.UnmarshalTypeError); ok {
 				err = errors.New(jsonErr.Field + " type mismatch")
 			} else if _, ok := rawError.(*json.SyntaxError); ok {
@@ -139,7 +149,10 @@ func panicToError(panicError any) (statusCode int, err error, internalError bool
 		}
 	}
 	if !internalError {
-		stack := string(debug.Stack())
+		stack = capturedStack
+		if stack == "" {
+			stack = string(debug.Stack())
+		}
 		lines := strings.Split(stack, "\n")
 		index := coll.SliceAnyIndexOf(lines, func(line string) bool {
 			return strings.Contains(line, "runtime/panic.go")
@@ -170,8 +183,16 @@ func recoverHandler() gin.HandlerFunc {
 		defer func() {
 			if panicError := recover(); panicError != nil {
 				var errMsg string
+				var capturedStack string
+				if recovered, ok := panicError.(*recoveredPanic); ok {
+					panicError, capturedStack = recovered.value, recovered.stack
+				}
 				// 将panic异常进行转换
-				status, err, internalError := panicToError(panicError)
+				status, err, internalError, stack := panicToError(ctx, panicError, capturedStack)
+				// 内部特殊错误用于中断请求流程 不上报
+				if _, ok := panicError.(*internalPanic); !ok {
+					reportPanic(ctx, panicError, err, stack)
+				}
 				if ginConfig.HidePanicErrorDetails { // 禁用异常信息显示
 					if !internalError {
 						errMsg = ""
@@ -195,8 +216,18 @@ func recoverHandler() gin.HandlerFunc {
 				} else {
 					statusCode = ctx.Writer.Status()
 				}
+				ctx.Set(ginCtxKeyPanic, panicError)
+				// 记录异常响应的原始状态码 未指定时视为StatusCodeException
+				if statusCode == 0 {
+					ctx.Set(ginCtxKeyResolvedStatus, StatusCodeException)
+				} else {
+					ctx.Set(ginCtxKeyResolvedStatus, statusCode)
+				}
 				var response Response
 				if !ginConfig.DisableBadHttpCodeResolver {
+					if errMsg == "" {
+						errMsg = localeBadHttpCodeMessage(ctx, statusCode)
+					}
 					response = ginConfig.BadHttpCodeResolver(statusCode, errMsg)
 					ctx.Writer.Header().Set("Content-Type", gin.MIMEJSON)
 				} else {
@@ -212,7 +243,7 @@ func recoverHandler() gin.HandlerFunc {
 
 		ctx.Next()
 		// 异常响应码处理
-		if !ginConfig.DisableBadHttpCodeResolver {
+		if !ginConfig.DisableBadHttpCodeResolver && !ctx.GetBool(ginCtxKeyBypassResolver) {
 			var statusCode int
 			var rewriter *responseRewriter
 			if v, ok := ctx.Writer.(*responseRewriter); ok {
@@ -230,7 +261,8 @@ func recoverHandler() gin.HandlerFunc {
 					return
 				}
 				logger.Logrus().Warningln("Bad response path:", ctx.Request.URL, "status code:", statusCode)
-				response := ginConfig.BadHttpCodeResolver(statusCode, "")
+				ctx.Set(ginCtxKeyResolvedStatus, statusCode)
+				response := ginConfig.BadHttpCodeResolver(statusCode, localeBadHttpCodeMessage(ctx, statusCode))
 				httpResponse(ctx, response)
 				if rewriter != nil {
 					rewriter.ResponseWriter.WriteHeader(rewriter.statusCode)
@@ -0,0 +1,293 @@
+package ginstarter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/acexy/golang-toolkit/logger"
+	"github.com/gin-gonic/gin"
+)
+
+/**
+IP访问控制
+未配置GinConfig.TrustedProxies及TrustedPlatform时使用连接的远端地址判断 防止X-Forwarded-For伪造
+配置后使用从受信任代理转发的请求头中获取的客户端IP
+可全局配置(GinConfig.IPFilter)或按Router配置(RouterInfo.IPFilter) Router配置的白名单优先 全局黑名单始终生效
+也可通过IPFilterInterceptor作为拦截器使用
+*/
+
+// IPFilterConfig IP访问控制配置 规则支持单个IP及CIDR 如 10.0.0.1 192.168.0.0/16 ::1
+type IPFilterConfig struct {
+	// 白名单 不为空时仅允许白名单内的IP访问
+	Allow []string
+	// 黑名单 优先于白名单
+	Deny []string
+	// 规则文件 与Allow及Deny合并 文件修改后自动重新加载
+	// 每行一条规则 格式为 allow|deny IP/CIDR 以#开头的行为注释
+	File string
+	// 规则文件检查间隔 默认10s
+	ReloadInterval time.Duration
+}
+
+type ipFilterRules struct {
+	allow []netip.Prefix
+	deny  []netip.Prefix
+}
+
+// IPFilter IP访问控制器
+type IPFilter struct {
+	config      IPFilterConfig
+	static      *ipFilterRules
+	rules       atomic.Pointer[ipFilterRules]
+	mutex       sync.Mutex
+	modTime     time.Time
+	nextCheckAt atomic.Int64
+}
+
+// NewIPFilter 创建IP访问控制器 规则或规则文件无效时返回错误
+func NewIPFilter(config *IPFilterConfig) (*IPFilter, error) {
+	filter := &IPFilter{config: *config}
+	if filter.config.ReloadInterval <= 0 {
+		filter.config.ReloadInterval = time.Second * 10
+	}
+	static := &ipFilterRules{}
+	var err error
+	if static.allow, err = parseIPPrefixes(config.Allow); err != nil {
+		return nil, err
+	}
+	if static.deny, err = parseIPPrefixes(config.Deny); err != nil {
+		return nil, err
+	}
+	filter.static = static
+	filter.rules.Store(static)
+	if config.File != "" {
+		if err = filter.Reload(); err != nil {
+			return nil, err
+		}
+	}
+	return filter, nil
+}
+
+// Reload 重新加载规则文件 加载失败时保留原有规则
+func (f *IPFilter) Reload() error {
+	if f.config.File == "" {
+		return nil
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.nextCheckAt.Store(time.Now().Add(f.config.ReloadInterval).UnixNano())
+	info, err := os.Stat(f.config.File)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(f.config.File)
+	if err != nil {
+		return err
+	}
+	fileRules, err := parseIPFilterFile(data)
+	if err != nil {
+		return err
+	}
+	rules := &ipFilterRules{
+		allow: append(append([]netip.Prefix{}, f.static.allow...), fileRules.allow...),
+		deny:  append(append([]netip.Prefix{}, f.static.deny...), fileRules.deny...),
+	}
+	f.rules.Store(rules)
+	f.modTime = info.ModTime()
+	return nil
+}
+
+// 规则文件修改后重新加载 按照ReloadInterval节流
+func (f *IPFilter) reloadIfModified() {
+	if f.config.File == "" || time.Now().UnixNano() < f.nextCheckAt.Load() {
+		return
+	}
+	info, err := os.Stat(f.config.File)
+	if err != nil {
+		f.nextCheckAt.Store(time.Now().Add(f.config.ReloadInterval).UnixNano())
+		logger.Logrus().Warningln("ip filter rule file unavailable:", err)
+		return
+	}
+	f.mutex.Lock()
+	modified := !info.ModTime().Equal(f.modTime)
+	f.mutex.Unlock()
+	if !modified {
+		f.nextCheckAt.Store(time.Now().Add(f.config.ReloadInterval).UnixNano())
+		return
+	}
+	if err = f.Reload(); err != nil {
+		logger.Logrus().Errorln("ip filter rule file reload failed:", err)
+		return
+	}
+	logger.Logrus().Infoln("ip filter rule file reloaded:", f.config.File)
+}
+
+// Allowed 判断IP是否允许访问 无法解析的IP仅在未配置任何规则时允许
+func (f *IPFilter) Allowed(ip string) bool {
+	f.reloadIfModified()
+	rules := f.rules.Load()
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return len(rules.allow) == 0 && len(rules.deny) == 0
+	}
+	addr = addr.Unmap()
+	if rules.denied(addr) {
+		return false
+	}
+	if len(rules.allow) == 0 {
+		return true
+	}
+	for _, prefix := range rules.allow {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Denied 判断IP是否在黑名单中
+func (f *IPFilter) Denied(ip string) bool {
+	f.reloadIfModified()
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	return f.rules.Load().denied(addr.Unmap())
+}
+
+func (r *ipFilterRules) denied(addr netip.Addr) bool {
+	for _, prefix := range r.deny {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// 访问控制 限流及访问日志使用的客户端IP 未配置受信任代理时gin信任所有代理 此时仅使用连接的远端地址
+func trustedClientIP(ctx *gin.Context) string {
+	if ginConfig != nil && ginConfig.TrustedProxies == nil && ginConfig.TrustedPlatform == "" {
+		return ctx.RemoteIP()
+	}
+	return ctx.ClientIP()
+}
+
+// IPFilterInterceptor IP访问控制拦截器 拒绝访问时响应403
+// match 满足指定条件才执行
+func IPFilterInterceptor(filter *IPFilter, match ...func(request *Request) bool) PreInterceptor {
+	return func(request *Request) (Response, bool, bool) {
+		if len(match) > 0 {
+			if !match[0](request) {
+				return nil, true, true
+			}
+		}
+		ip := trustedClientIP(request.ctx)
+		if !filter.Allowed(ip) {
+			logger.Logrus().Warningln("ip filter rejected ip:", ip, "path:", request.RequestPath())
+			return RespHttpStatusCode(http.StatusForbidden), false, false
+		}
+		return nil, true, true
+	}
+}
+
+var globalIPFilter *IPFilter
+var routerIPFilters []*routerScoped[*IPFilter]
+
+func initIPFilter(filter *IPFilter) {
+	globalIPFilter = filter
+	routerIPFilters = nil
+}
+
+func registerRouterIPFilter(groupPath string, filter *IPFilter) {
+	routerIPFilters = append(routerIPFilters, newRouterScoped(groupPath, filter))
+}
+
+// IP访问控制中间件 作为全局中间件注册 同样作用于404及405处理
+func ipFilterHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		filter, ok := matchRouterScoped(routerIPFilters, ctx.Request.URL.Path)
+		if !ok {
+			filter = globalIPFilter
+		}
+		if filter == nil {
+			return
+		}
+		ip := trustedClientIP(ctx)
+		// Router配置仅替换白名单 全局黑名单仍然生效
+		if !filter.Allowed(ip) || filter != globalIPFilter && globalIPFilter != nil && globalIPFilter.Denied(ip) {
+			logger.Logrus().Warningln("ip filter rejected ip:", ip, "path:", ctx.Request.URL.Path)
+			httpResponse(ctx, RespHttpStatusCode(http.StatusForbidden))
+			ctx.Abort()
+		}
+	}
+}
+
+func parseIPPrefixes(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		prefix, err := parseIPPrefix(value)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
+
+func parseIPPrefix(value string) (netip.Prefix, error) {
+	value = strings.TrimSpace(value)
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+func parseIPFilterFile(data []byte) (*ipFilterRules, error) {
+	rules := &ipFilterRules{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("ip filter rule file line %d: invalid rule %q", line, text)
+		}
+		prefix, err := parseIPPrefix(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("ip filter rule file line %d: %w", line, err)
+		}
+		switch strings.ToLower(fields[0]) {
+		case "allow":
+			rules.allow = append(rules.allow, prefix)
+		case "deny":
+			rules.deny = append(rules.deny, prefix)
+		default:
+			return nil, fmt.Errorf("ip filter rule file line %d: unknown action %q", line, fields[0])
+		}
+	}
+	return rules, scanner.Err()
+}
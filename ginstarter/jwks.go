@@ -0,0 +1,278 @@
+package ginstarter
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/acexy/golang-toolkit/logger"
+)
+
+// JwksKeySetConfig 远程JWKS密钥集配置
+type JwksKeySetConfig struct {
+	// * JWKS地址
+	Url string
+	// 本地缓存文件 远程获取成功后写入 首次获取失败时使用
+	CacheFile string
+	// 刷新间隔 默认1小时
+	RefreshInterval time.Duration
+	// 遇到未知kid时触发刷新的最小间隔 默认1分钟
+	MinRefreshInterval time.Duration
+	// 自定义http客户端 默认超时10秒
+	HttpClient *http.Client
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+type jwtKey struct {
+	kid string
+	alg string
+	key any
+}
+
+type jwksKeySet struct {
+	keys []*jwtKey
+}
+
+func (j *jwksKeySet) Key(kid, alg string) (any, error) {
+	return lookupJwk(j.keys, kid, alg)
+}
+
+// NewJwksFileKeySet 从本地JWKS文件加载密钥集
+func NewJwksFileKeySet(path string) (JwtKeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := parseJwks(data)
+	if err != nil {
+		return nil, err
+	}
+	return &jwksKeySet{keys: keys}, nil
+}
+
+type jwksUrlKeySet struct {
+	config      JwksKeySetConfig
+	mutex       sync.RWMutex
+	keys        []*jwtKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+}
+
+// NewJwksUrlKeySet 创建远程JWKS密钥集 首次使用时获取 并按照刷新间隔更新
+func NewJwksUrlKeySet(config *JwksKeySetConfig) JwtKeySet {
+	keySet := &jwksUrlKeySet{config: *config}
+	if keySet.config.RefreshInterval <= 0 {
+		keySet.config.RefreshInterval = time.Hour
+	}
+	if keySet.config.MinRefreshInterval <= 0 {
+		keySet.config.MinRefreshInterval = time.Minute
+	}
+	if keySet.config.HttpClient == nil {
+		keySet.config.HttpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return keySet
+}
+
+func (j *jwksUrlKeySet) Key(kid, alg string) (any, error) {
+	j.mutex.RLock()
+	keys := j.keys
+	expired := time.Since(j.fetchedAt) > j.config.RefreshInterval
+	j.mutex.RUnlock()
+
+	if keys == nil || expired {
+		keys = j.refresh(false)
+	}
+	key, err := lookupJwk(keys, kid, alg)
+	if errors.Is(err, ErrJwtKeyNotFound) {
+		// 密钥可能已轮换 尝试重新获取
+		if refreshed := j.refresh(true); refreshed != nil {
+			return lookupJwk(refreshed, kid, alg)
+		}
+	}
+	return key, err
+}
+
+// 获取远程密钥集 获取失败时保留已有密钥集
+func (j *jwksUrlKeySet) refresh(force bool) []*jwtKey {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if time.Since(j.lastAttempt) < j.config.MinRefreshInterval {
+		return j.keys
+	}
+	if !force && j.keys != nil && time.Since(j.fetchedAt) <= j.config.RefreshInterval {
+		return j.keys
+	}
+	j.lastAttempt = time.Now()
+	data, err := j.fetch()
+	if err == nil {
+		var keys []*jwtKey
+		if keys, err = parseJwks(data); err == nil {
+			j.keys = keys
+			j.fetchedAt = time.Now()
+			if j.config.CacheFile != "" {
+				if err := os.WriteFile(j.config.CacheFile, data, 0600); err != nil {
+					logger.Logrus().Warningln("write jwks cache file error:", err)
+				}
+			}
+			return j.keys
+		}
+	}
+	logger.Logrus().Warningln("fetch jwks error url:", j.config.Url, err)
+	if j.keys == nil && j.config.CacheFile != "" {
+		if data, err := os.ReadFile(j.config.CacheFile); err == nil {
+			if keys, err := parseJwks(data); err == nil {
+				j.keys = keys
+			}
+		}
+	}
+	return j.keys
+}
+
+func (j *jwksUrlKeySet) fetch() ([]byte, error) {
+	response, err := j.config.HttpClient.Get(j.config.Url)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", response.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(response.Body, 1<<20))
+}
+
+// 携带kid时仅使用对应的密钥 未携带kid时仅在唯一一个密钥与alg匹配时使用
+func lookupJwk(keys []*jwtKey, kid, alg string) (any, error) {
+	var matched any
+	for _, key := range keys {
+		if kid != "" && key.kid != kid {
+			continue
+		}
+		if key.alg != "" && key.alg != alg {
+			continue
+		}
+		if jwtKeyMatchAlgorithm(key.key, alg) {
+			if kid != "" {
+				return key.key, nil
+			}
+			if matched != nil {
+				return nil, ErrJwtKeyNotFound
+			}
+			matched = key.key
+		}
+	}
+	if matched == nil {
+		return nil, ErrJwtKeyNotFound
+	}
+	return matched, nil
+}
+
+// 解析JWKS 忽略不支持及非签名用途的密钥
+func parseJwks(data []byte) ([]*jwtKey, error) {
+	var jwks struct {
+		Keys []*jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, err
+	}
+	keys := make([]*jwtKey, 0, len(jwks.Keys))
+	for _, v := range jwks.Keys {
+		if v.Use != "" && v.Use != "sig" {
+			continue
+		}
+		key, err := v.publicKey()
+		if err != nil {
+			logger.Logrus().Warningln("skip jwk kid:", v.Kid, err)
+			continue
+		}
+		keys = append(keys, &jwtKey{kid: v.Kid, alg: v.Alg, key: key})
+	}
+	return keys, nil
+}
+
+func (j *jwk) publicKey() (any, error) {
+	switch j.Kty {
+	case "oct":
+		return jwkBytes(j.K)
+	case "RSA":
+		n, err := jwkBytes(j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := jwkBytes(j.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", j.Crv)
+		}
+		x, err := jwkBytes(j.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := jwkBytes(j.Y)
+		if err != nil {
+			return nil, err
+		}
+		publicKey := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(publicKey.X, publicKey.Y) {
+			return nil, errors.New("invalid ec point")
+		}
+		return publicKey, nil
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %s", j.Crv)
+		}
+		x, err := jwkBytes(j.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", j.Kty)
+}
+
+func jwkBytes(value string) ([]byte, error) {
+	if value == "" {
+		return nil, errors.New("missing key parameter")
+	}
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
@@ -0,0 +1,532 @@
+package ginstarter
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/acexy/golang-toolkit/logger"
+)
+
+/**
+JWT认证拦截器
+支持HS256/384/512 RS256/384/512 PS256/384/512 ES256/384/512 EdDSA签名算法
+校验通过后claims将作为认证主体绑定到请求上下文 可通过Request.Principal()或PrincipalAs获取
+*/
+
+var (
+	ErrJwtMissing      = errors.New("jwt: token missing")
+	ErrJwtMalformed    = errors.New("jwt: token malformed")
+	ErrJwtAlgorithm    = errors.New("jwt: algorithm not allowed")
+	ErrJwtKeyNotFound  = errors.New("jwt: verification key not found")
+	ErrJwtSignature    = errors.New("jwt: signature invalid")
+	ErrJwtExpired      = errors.New("jwt: token expired")
+	ErrJwtNotValidYet  = errors.New("jwt: token not valid yet")
+	ErrJwtIssuer       = errors.New("jwt: issuer invalid")
+	ErrJwtAudience     = errors.New("jwt: audience invalid")
+	ErrJwtNoExpiration = errors.New("jwt: expiration required")
+)
+
+// JwtKeySet 验证密钥集
+// 返回的密钥类型: HS算法为[]byte RS/PS算法为*rsa.PublicKey ES算法为*ecdsa.PublicKey EdDSA算法为ed25519.PublicKey
+type JwtKeySet interface {
+	// Key 根据token头部的kid及alg获取验证密钥 kid可能为空
+	Key(kid, alg string) (any, error)
+}
+
+// JwtConfig JWT认证配置
+type JwtConfig struct {
+	// * 验证密钥集
+	KeySet JwtKeySet
+	// 允许的签名算法 默认允许与密钥类型匹配的所有算法
+	Algorithms []string
+
+	// 签发者 不为空时校验iss
+	Issuer string
+	// 受众 不为空时要求aud包含其中任意一个
+	Audience []string
+	// 校验exp/nbf时允许的时钟偏差
+	Leeway time.Duration
+	// 要求token必须包含exp
+	RequireExpiration bool
+
+	// 从Cookie获取token 在Authorization请求头不存在时使用
+	CookieName string
+	// 从Query参数获取token 在Authorization请求头及Cookie不存在时使用
+	QueryParamName string
+
+	// WWW-Authenticate响应头中的realm
+	Realm string
+	// 未携带token时允许请求继续 携带了无效token仍将拒绝
+	Optional bool
+	// 将claims转换为自定义认证主体 默认使用*JwtClaims
+	PrincipalFunc func(claims *JwtClaims) (Principal, error)
+}
+
+// JwtClaims JWT标准声明
+type JwtClaims struct {
+	Iss string
+	Sub string
+	Aud []string
+	Exp int64
+	Nbf int64
+	Iat int64
+	Jti string
+	// 全部声明
+	Raw map[string]any
+
+	header  map[string]any
+	payload []byte
+}
+
+// Subject 实现Principal接口
+func (c *JwtClaims) Subject() string {
+	return c.Sub
+}
+
+// Roles 实现RolesPrincipal接口 取自roles声明
+func (c *JwtClaims) Roles() []string {
+	return jwtClaimStrings(c.Raw["roles"])
+}
+
+// Permissions 实现PermissionsPrincipal接口 取自permissions声明 不存在时取自scope/scp声明
+func (c *JwtClaims) Permissions() []string {
+	if permissions, ok := c.Raw["permissions"]; ok {
+		return jwtClaimStrings(permissions)
+	}
+	if scope, ok := c.Raw["scope"]; ok {
+		return jwtClaimStrings(scope)
+	}
+	return jwtClaimStrings(c.Raw["scp"])
+}
+
+// Header 获取token头部参数
+func (c *JwtClaims) Header(name string) (any, bool) {
+	v, ok := c.header[name]
+	return v, ok
+}
+
+// Decode 将全部声明解析至自定义结构体
+func (c *JwtClaims) Decode(object any) error {
+	return json.Unmarshal(c.payload, object)
+}
+
+// JwtInterceptor JWT认证拦截器 认证失败时响应401及WWW-Authenticate
+// match 满足指定条件才执行
+func JwtInterceptor(config *JwtConfig, match ...func(request *Request) bool) PreInterceptor {
+	return func(request *Request) (Response, bool, bool) {
+		if len(match) > 0 {
+			if !match[0](request) {
+				return nil, true, true
+			}
+		}
+		token := jwtToken(request, config)
+		if token == "" {
+			if config.Optional {
+				return nil, true, true
+			}
+			return jwtUnauthorized(request, config, ErrJwtMissing), false, false
+		}
+		claims, err := VerifyJwt(token, config)
+		if err != nil {
+			logger.Logrus().Debugln("jwt verify failed path:", request.RequestPath(), err)
+			return jwtUnauthorized(request, config, err), false, false
+		}
+		var principal Principal = claims
+		if config.PrincipalFunc != nil {
+			principal, err = config.PrincipalFunc(claims)
+			if err != nil {
+				logger.Logrus().Debugln("jwt principal rejected path:", request.RequestPath(), err)
+				return jwtUnauthorized(request, config, err), false, false
+			}
+		}
+		request.SetPrincipal(principal)
+		return nil, true, true
+	}
+}
+
+// 按照 Authorization > Cookie > Query 的顺序获取token
+func jwtToken(request *Request, config *JwtConfig) string {
+	authorization := request.GetHeader("Authorization")
+	if len(authorization) > 7 && strings.EqualFold(authorization[:7], "Bearer ") {
+		return strings.TrimSpace(authorization[7:])
+	}
+	if config.CookieName != "" {
+		if token, err := request.GetCookie(config.CookieName); err == nil && token != "" {
+			return token
+		}
+	}
+	if config.QueryParamName != "" {
+		if token, ok := request.GetQueryParam(config.QueryParamName); ok {
+			return token
+		}
+	}
+	return ""
+}
+
+// 响应401 并按照RFC 6750设置WWW-Authenticate
+func jwtUnauthorized(request *Request, config *JwtConfig, err error) Response {
+	challenge := "Bearer"
+	if config.Realm != "" {
+		challenge += fmt.Sprintf(` realm="%s"`, config.Realm)
+	}
+	if !errors.Is(err, ErrJwtMissing) {
+		if config.Realm != "" {
+			challenge += ","
+		}
+		challenge += fmt.Sprintf(` error="invalid_token", error_description="%s"`, jwtErrorDescription(err))
+	}
+	request.ctx.Header("WWW-Authenticate", challenge)
+	return RespHttpStatusCode(http.StatusUnauthorized)
+}
+
+// 按错误类别返回固定的error_description 不将原始错误信息写入响应头
+func jwtErrorDescription(err error) string {
+	switch {
+	case errors.Is(err, ErrJwtExpired):
+		return "token expired"
+	case errors.Is(err, ErrJwtNotValidYet):
+		return "token not valid yet"
+	case errors.Is(err, ErrJwtIssuer), errors.Is(err, ErrJwtAudience), errors.Is(err, ErrJwtNoExpiration):
+		return "token claims invalid"
+	case errors.Is(err, ErrJwtMalformed):
+		return "token malformed"
+	default:
+		return "token invalid"
+	}
+}
+
+// VerifyJwt 校验token签名及声明
+func VerifyJwt(token string, config *JwtConfig) (*JwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrJwtMalformed
+	}
+	headerBytes, err := jwtDecodeSegment(parts[0])
+	if err != nil {
+		return nil, ErrJwtMalformed
+	}
+	var header map[string]any
+	if err = json.Unmarshal(headerBytes, &header); err != nil {
+		return nil, ErrJwtMalformed
+	}
+	alg, _ := header["alg"].(string)
+	kid, _ := header["kid"].(string)
+	if alg == "" || strings.EqualFold(alg, "none") {
+		return nil, ErrJwtAlgorithm
+	}
+	if len(config.Algorithms) > 0 && !jwtContains(config.Algorithms, alg) {
+		return nil, ErrJwtAlgorithm
+	}
+	if config.KeySet == nil {
+		return nil, ErrJwtKeyNotFound
+	}
+	key, err := config.KeySet.Key(kid, alg)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, ErrJwtKeyNotFound
+	}
+	signature, err := jwtDecodeSegment(parts[2])
+	if err != nil {
+		return nil, ErrJwtMalformed
+	}
+	if err = jwtVerifySignature(alg, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	payload, err := jwtDecodeSegment(parts[1])
+	if err != nil {
+		return nil, ErrJwtMalformed
+	}
+	claims, err := parseJwtClaims(payload)
+	if err != nil {
+		return nil, ErrJwtMalformed
+	}
+	claims.header = header
+	if err = validateJwtClaims(claims, config); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func parseJwtClaims(payload []byte) (*JwtClaims, error) {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var raw map[string]any
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+	claims := &JwtClaims{Raw: raw, payload: payload}
+	claims.Iss, _ = raw["iss"].(string)
+	claims.Sub, _ = raw["sub"].(string)
+	claims.Jti, _ = raw["jti"].(string)
+	switch aud := raw["aud"].(type) {
+	case string:
+		claims.Aud = []string{aud}
+	case []any:
+		for _, v := range aud {
+			if s, ok := v.(string); ok {
+				claims.Aud = append(claims.Aud, s)
+			}
+		}
+	}
+	var err error
+	if claims.Exp, err = jwtNumericDate(raw["exp"]); err != nil {
+		return nil, err
+	}
+	if claims.Nbf, err = jwtNumericDate(raw["nbf"]); err != nil {
+		return nil, err
+	}
+	if claims.Iat, err = jwtNumericDate(raw["iat"]); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// 解析字符串数组或空格分隔的字符串声明
+func jwtClaimStrings(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+func jwtNumericDate(value any) (int64, error) {
+	if value == nil {
+		return 0, nil
+	}
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, ErrJwtMalformed
+	}
+	if v, err := number.Int64(); err == nil {
+		return v, nil
+	}
+	v, err := number.Float64()
+	return int64(v), err
+}
+
+func validateJwtClaims(claims *JwtClaims, config *JwtConfig) error {
+	now := time.Now()
+	leeway := config.Leeway
+	if claims.Exp != 0 {
+		if now.After(time.Unix(claims.Exp, 0).Add(leeway)) {
+			return ErrJwtExpired
+		}
+	} else if config.RequireExpiration {
+		return ErrJwtNoExpiration
+	}
+	if claims.Nbf != 0 && now.Before(time.Unix(claims.Nbf, 0).Add(-leeway)) {
+		return ErrJwtNotValidYet
+	}
+	if config.Issuer != "" && claims.Iss != config.Issuer {
+		return ErrJwtIssuer
+	}
+	if len(config.Audience) > 0 {
+		matched := false
+		for _, aud := range claims.Aud {
+			if jwtContains(config.Audience, aud) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return ErrJwtAudience
+		}
+	}
+	return nil
+}
+
+func jwtVerifySignature(alg string, key any, signingInput, signature []byte) error {
+	if alg == "EdDSA" {
+		publicKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return ErrJwtAlgorithm
+		}
+		if !ed25519.Verify(publicKey, signingInput, signature) {
+			return ErrJwtSignature
+		}
+		return nil
+	}
+	if len(alg) != 5 {
+		return ErrJwtAlgorithm
+	}
+	var hash crypto.Hash
+	switch alg[2:] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return ErrJwtAlgorithm
+	}
+	hasher := hash.New()
+	switch alg[:2] {
+	case "HS":
+		secret, ok := key.([]byte)
+		if !ok {
+			return ErrJwtAlgorithm
+		}
+		mac := hmac.New(hash.New, secret)
+		mac.Write(signingInput)
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return ErrJwtSignature
+		}
+		return nil
+	case "RS", "PS":
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrJwtAlgorithm
+		}
+		hasher.Write(signingInput)
+		var err error
+		if alg[0] == 'R' {
+			err = rsa.VerifyPKCS1v15(publicKey, hash, hasher.Sum(nil), signature)
+		} else {
+			err = rsa.VerifyPSS(publicKey, hash, hasher.Sum(nil), signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		if err != nil {
+			return ErrJwtSignature
+		}
+		return nil
+	case "ES":
+		publicKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || publicKey.Curve.Params().BitSize != jwtCurveBits(alg) {
+			return ErrJwtAlgorithm
+		}
+		keyBytes := (publicKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != keyBytes*2 {
+			return ErrJwtSignature
+		}
+		hasher.Write(signingInput)
+		r := new(big.Int).SetBytes(signature[:keyBytes])
+		s := new(big.Int).SetBytes(signature[keyBytes:])
+		if !ecdsa.Verify(publicKey, hasher.Sum(nil), r, s) {
+			return ErrJwtSignature
+		}
+		return nil
+	}
+	return ErrJwtAlgorithm
+}
+
+// ES512使用P-521曲线
+func jwtCurveBits(alg string) int {
+	if alg == "ES512" {
+		return 521
+	}
+	bits := 0
+	_, _ = fmt.Sscanf(alg[2:], "%d", &bits)
+	return bits
+}
+
+// 判断密钥是否可用于指定算法
+func jwtKeyMatchAlgorithm(key any, alg string) bool {
+	switch k := key.(type) {
+	case []byte:
+		return strings.HasPrefix(alg, "HS")
+	case *rsa.PublicKey:
+		return strings.HasPrefix(alg, "RS") || strings.HasPrefix(alg, "PS")
+	case *ecdsa.PublicKey:
+		return strings.HasPrefix(alg, "ES") && k.Curve.Params().BitSize == jwtCurveBits(alg)
+	case ed25519.PublicKey:
+		return alg == "EdDSA"
+	}
+	return false
+}
+
+func jwtDecodeSegment(segment string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+}
+
+func jwtContains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+type staticJwtKeySet struct {
+	keys map[string]any
+}
+
+// 携带kid时仅使用对应的密钥 未携带kid时仅在唯一一个密钥与alg匹配时使用
+func (s *staticJwtKeySet) Key(kid, alg string) (any, error) {
+	if kid != "" {
+		if key, ok := s.keys[kid]; ok && jwtKeyMatchAlgorithm(key, alg) {
+			return key, nil
+		}
+		return nil, ErrJwtKeyNotFound
+	}
+	var matched any
+	for _, key := range s.keys {
+		if jwtKeyMatchAlgorithm(key, alg) {
+			if matched != nil {
+				return nil, ErrJwtKeyNotFound
+			}
+			matched = key
+		}
+	}
+	if matched == nil {
+		return nil, ErrJwtKeyNotFound
+	}
+	return matched, nil
+}
+
+// NewJwtKeySet 使用固定密钥创建密钥集 key: kid value: 密钥
+// 携带kid的token只能使用对应kid的密钥验证 未携带kid的token仅在唯一一个密钥与alg匹配时可验证
+func NewJwtKeySet(keys map[string]any) JwtKeySet {
+	return &staticJwtKeySet{keys: keys}
+}
+
+// NewJwtSecretKeySet 使用HS算法共享密钥创建密钥集 token不能携带kid
+func NewJwtSecretKeySet(secret []byte) JwtKeySet {
+	return NewJwtKeySet(map[string]any{"": secret})
+}
+
+// ParseJwtPublicKeyPEM 解析PEM格式的公钥或证书 支持RSA ECDSA Ed25519
+func ParseJwtPublicKeyPEM(data []byte) (any, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("jwt: invalid pem data")
+	}
+	switch block.Type {
+	case "CERTIFICATE":
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return certificate.PublicKey, nil
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/acexy/golang-toolkit/logger"
 	"github.com/acexy/golang-toolkit/util/coll"
 	"github.com/acexy/golang-toolkit/util/net"
 	"github.com/gin-gonic/gin"
@@ -39,6 +40,21 @@ type GinConfig struct {
 	HidePanicErrorDetails bool
 	// 全局异常响应处理器 如果不指定则使用默认方式
 	PanicResolver PanicResolver
+	// Panic上报配置 将框架未知错误异步上报至外部异常收集服务
+	PanicReport *PanicReportConfig
+	// 全局请求超时时间 作用于通过RouterWrapper注册的路由 可被RouterInfo.Timeout及RouterWrapper.WithTimeout覆盖
+	// 超时后Request.Context()将被取消 并通过BadHttpCodeResolver响应StatusCodeTimeout
+	RequestTimeout time.Duration
+
+	// HandlerWrapper返回错误时的映射器 按照顺序匹配 未匹配的错误将进入panic流程
+	// 预定义的业务错误BizError将始终优先处理
+	ErrorMappers []ErrorMapper
+
+	// 全局并发限制 超出限制且排队超时的请求将响应StatusCodeServiceUnavailable
+	ConcurrencyLimit *ConcurrencyLimitConfig
+
+	// 路由授权校验器 默认使用认证主体实现的RolesPrincipal及PermissionsPrincipal校验
+	Authorizer Authorizer
 
 	// 禁用异常http响应码Resolver
 	DisableBadHttpCodeResolver bool
@@ -62,6 +78,38 @@ type GinConfig struct {
 
 	// 启用TraceId响应
 	TraceIdResponse func() string
+	// TraceId响应头名称 默认Trace-Id
+	TraceIdHeaderName string
+
+	// 请求ID配置 接受调用方传入的请求ID并在响应中回传
+	RequestId *RequestIdConfig
+
+	// 验证器拓展配置 注册自定义验证tag、别名、结构体级别验证及自定义类型
+	ValidatorConfig *ValidatorConfig
+
+	// 国际化配置 启用后验证信息与默认状态信息将根据请求语言响应
+	I18nConfig *I18nConfig
+
+	// 会话配置 启用后可通过Request.Session()使用会话
+	SessionConfig *SessionConfig
+
+	// 访问日志配置
+	AccessLog *AccessLogConfig
+	// 请求响应转储配置 仅在DebugModule下生效 用于排查问题
+	Dump *DumpConfig
+	// Prometheus指标配置
+	Metrics *MetricsConfig
+	// 链路追踪配置 启用后Trace-Id响应头使用Span的TraceId
+	Tracing *TracingConfig
+	// 运维诊断端点配置 pprof 协程堆栈 构建信息 配置及路由表
+	Admin *AdminConfig
+
+	// 全局跨域配置 可被RouterInfo.Cors覆盖 preflight请求将在路由匹配前直接响应
+	CorsConfig *CorsConfig
+
+	// 全局IP访问控制 白名单可被RouterInfo.IPFilter覆盖 黑名单始终生效
+	// 未配置TrustedProxies及TrustedPlatform时使用连接的远端地址判断
+	IPFilter *IPFilter
 
 	// ========== gin config
 	DebugModule        bool
@@ -72,6 +120,13 @@ type GinConfig struct {
 
 	// 禁用尝试获取转发真实IP
 	DisableForwardedByClientIP bool
+	// 受信任的代理IP或CIDR 仅当请求来自受信任的代理时才从RemoteIPHeaders中获取真实IP
+	// 不设置时信任所有代理 设置为空切片时不信任任何代理 不设置时IP访问控制仅使用连接的远端地址
+	TrustedProxies []string
+	// 获取真实IP的请求头 默认X-Forwarded-For X-Real-IP
+	RemoteIPHeaders []string
+	// 受信任的平台请求头 设置后优先使用该请求头获取真实IP 如gin.PlatformCloudflare
+	TrustedPlatform string
 }
 
 type GinStarter struct {
@@ -116,8 +171,86 @@ func (g *GinStarter) Setting() *parent.Setting {
 }
 
 func (g *GinStarter) Start() (any, error) {
-	var err error
 	config := g.getConfig()
+	engine, err := buildEngine(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.ListenAddress == "" {
+		config.ListenAddress = ":8080"
+	}
+
+	newServerContext()
+	server = &http.Server{
+		Addr:        config.ListenAddress,
+		Handler:     engine,
+		BaseContext: baseContext,
+	}
+
+	errChn := make(chan error)
+	go func() {
+		if config.UseReusePortModel {
+			listener, err := reuseport.Listen("tcp", config.ListenAddress)
+			if err != nil {
+				errChn <- err
+			} else {
+				if err = server.Serve(listener); err != nil {
+					errChn <- err
+				}
+			}
+		} else {
+			if err = server.ListenAndServe(); err != nil {
+				errChn <- err
+			}
+		}
+	}()
+
+	select {
+	case <-time.After(time.Second):
+		return engine, nil
+	case err = <-errChn:
+		releaseEngine()
+		cancelServerContext()
+		return engine, err
+	}
+}
+
+// BuildEngine 构建包含全部中间件及路由的gin引擎并执行InitFunc 但不监听端口 用于进程内测试
+// 返回的关闭函数用于释放独立监听的指标及诊断服务 引擎依赖全局状态 不可与Start同时使用
+func (g *GinStarter) BuildEngine() (*gin.Engine, func(), error) {
+	config := g.getConfig()
+	engine, err := buildEngine(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	if config.InitFunc != nil {
+		config.InitFunc(engine)
+	}
+	newServerContext()
+	return engine, func() {
+		releaseEngine()
+		cancelServerContext()
+	}, nil
+}
+
+// 释放构建引擎时启动的独立监听服务及后台协程
+func releaseEngine() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	stopMetrics(ctx)
+	stopAdmin(ctx)
+	stopTracing(ctx)
+	stopPanicReport(ctx)
+}
+
+// 构建gin引擎 注册中间件及路由 失败时释放已启动的独立监听服务
+func buildEngine(config *GinConfig) (engine *gin.Engine, err error) {
+	defer func() {
+		if err != nil {
+			releaseEngine()
+		}
+	}()
 	if config.DebugModule {
 		gin.SetMode(gin.DebugMode)
 	} else {
@@ -128,7 +261,34 @@ func (g *GinStarter) Start() (any, error) {
 	gin.DefaultErrorWriter = &logrusLogger{level: logrus.ErrorLevel}
 
 	ginEngine = gin.New()
-	registerValidators()
+	registerValidators(config.ValidatorConfig)
+	initI18n(config.I18nConfig)
+	initRequestId(config.RequestId)
+	initPanicReport(config.PanicReport)
+	if config.RequestId != nil {
+		ginEngine.Use(requestIdHandler())
+	}
+	initTracing(config.Tracing)
+	if config.Tracing != nil {
+		ginEngine.Use(tracingHandler())
+	}
+	if config.AccessLog != nil {
+		// 在异常恢复之前注册 记录最终响应
+		ginEngine.Use(accessLogHandler(config.AccessLog))
+	}
+	if config.Dump != nil {
+		if config.DebugModule {
+			ginEngine.Use(dumpHandler(config.Dump))
+		} else {
+			logger.Logrus().Warningln("request dump is ignored because DebugModule is disabled")
+		}
+	}
+	if err = initMetrics(config.Metrics); err != nil {
+		return nil, err
+	}
+	if config.Metrics != nil {
+		ginEngine.Use(metricsHandler())
+	}
 	ginEngine.Use(recoverHandler())
 	if config.PanicResolver == nil {
 		config.PanicResolver = panicResolver
@@ -139,11 +299,30 @@ func (g *GinStarter) Start() (any, error) {
 	}
 
 	ginEngine.ForwardedByClientIP = !config.DisableForwardedByClientIP
+	if config.TrustedProxies != nil {
+		if err = ginEngine.SetTrustedProxies(config.TrustedProxies); err != nil {
+			return nil, err
+		}
+	}
+	if len(config.RemoteIPHeaders) > 0 {
+		ginEngine.RemoteIPHeaders = config.RemoteIPHeaders
+	}
+	ginEngine.TrustedPlatform = config.TrustedPlatform
+	// gin.Context作为context.Context使用时回退至Request.Context() 使其同样携带TraceId 认证主体及ContextKey数据 并在请求结束或服务停止时取消
+	ginEngine.ContextWithFallback = true
 
 	if !config.DisableMethodNotAllowedError {
 		ginEngine.HandleMethodNotAllowed = true
 	}
 
+	if err = initSession(config.SessionConfig); err != nil {
+		return nil, err
+	}
+	if config.SessionConfig != nil {
+		// 需在responseRewriteHandler之前注册 保证会话在最终响应写入前保存
+		ginEngine.Use(sessionHandler())
+	}
+
 	if !config.DisableBadHttpCodeResolver {
 		ginEngine.Use(responseRewriteHandler())
 		if config.BadHttpCodeResolver == nil {
@@ -154,6 +333,18 @@ func (g *GinStarter) Start() (any, error) {
 	if config.ResponseDataStructDecoder == nil {
 		config.ResponseDataStructDecoder = responseJsonDataStructDecoder{}
 	}
+	initIPFilter(config.IPFilter)
+	// Router级别的IP访问控制在注册路由时才能获取 始终注册该中间件
+	ginEngine.Use(ipFilterHandler())
+	if err = initCors(config.CorsConfig); err != nil {
+		return nil, err
+	}
+	// Router级别的跨域配置在注册路由时才能获取 始终注册跨域中间件 且需在限流及拦截器之前响应preflight请求
+	ginEngine.Use(corsHandler())
+	if config.ConcurrencyLimit != nil {
+		ginEngine.Use(newConcurrencyLimiter(config.ConcurrencyLimit).handler())
+	}
+
 	config.GlobalPreInterceptors = coll.SliceFilter(config.GlobalPreInterceptors, func(p PreInterceptor) bool {
 		return p != nil
 	})
@@ -217,56 +408,40 @@ func (g *GinStarter) Start() (any, error) {
 		}
 	})
 
+	config.ErrorMappers = coll.SliceFilter(config.ErrorMappers, func(m ErrorMapper) bool {
+		return m != nil
+	})
+
 	config.Routers = coll.SliceFilter(config.Routers, func(r Router) bool {
 		return r != nil
 	})
-	if len(config.Routers) > 0 {
-		registerRouter(ginEngine, config.Routers)
-	}
-
-	if config.ListenAddress == "" {
-		config.ListenAddress = ":8080"
+	resetRouteTable()
+	registerMetricsRoute(ginEngine)
+	if err = initAdmin(ginEngine, config.Admin); err != nil {
+		return nil, err
 	}
-
-	server = &http.Server{
-		Addr:    config.ListenAddress,
-		Handler: ginEngine,
-	}
-
-	errChn := make(chan error)
-	go func() {
-		if config.UseReusePortModel {
-			listener, err := reuseport.Listen("tcp", config.ListenAddress)
-			if err != nil {
-				errChn <- err
-			} else {
-				if err = server.Serve(listener); err != nil {
-					errChn <- err
-				}
-			}
-		} else {
-			if err = server.ListenAndServe(); err != nil {
-				errChn <- err
-			}
+	if len(config.Routers) > 0 {
+		if err = registerRouter(ginEngine, config.Routers); err != nil {
+			return nil, err
 		}
-	}()
-
-	select {
-	case <-time.After(time.Second):
-		return ginEngine, nil
-	case err = <-errChn:
-		return ginEngine, err
 	}
+	return ginEngine, nil
 }
 
+// Stop 停止服务 在maxWaitTime内等待未完成的请求 超时后仍未完成请求的Request.Context()将被取消
 func (g *GinStarter) Stop(maxWaitTime time.Duration) (gracefully, stopped bool, err error) {
 	ctx, cancel := context.WithTimeout(context.Background(), maxWaitTime)
 	defer cancel()
+	defer cancelServerContext()
+	stopMetrics(ctx)
+	stopAdmin(ctx)
 	if err = server.Shutdown(ctx); err != nil {
 		gracefully = false
 	} else {
 		gracefully = true
 	}
+	stopTracing(ctx)
+	stopPanicReport(ctx)
 	stopped = !net.Telnet(g.getConfig().ListenAddress, time.Second)
 	return
 }
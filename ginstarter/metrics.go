@@ -0,0 +1,382 @@
+package ginstarter
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/acexy/golang-toolkit/logger"
+	"github.com/gin-gonic/gin"
+)
+
+/**
+Prometheus指标
+记录请求数 耗时 请求及响应大小 并发请求数及panic次数 以请求方法 路由模板及状态码分类作为标签
+未匹配路由的请求使用unmatched作为路由标签 防止标签基数膨胀
+*/
+
+const metricsUnmatchedRoute = "unmatched"
+
+var (
+	defaultMetricsDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	defaultMetricsSizeBuckets     = []float64{100, 1000, 10000, 100000, 1000000, 10000000}
+)
+
+// MetricsConfig 指标配置
+type MetricsConfig struct {
+	// 指标路径 默认/metrics
+	Path string
+	// 独立的监听地址 如 :9090 设置后指标仅通过该地址暴露 不注册到业务服务
+	ListenAddress string
+	// 指标名称前缀 如 myapp 则指标名称为 myapp_http_server_requests_total
+	Namespace string
+	// 请求耗时分桶(秒) 默认0.005至10
+	DurationBuckets []float64
+	// 请求及响应大小分桶(字节) 默认100至10M
+	SizeBuckets []float64
+	// 不统计的请求路径 以*结尾时按前缀匹配
+	ExcludePaths []string
+	// 禁用Go运行时指标
+	DisableRuntimeMetrics bool
+}
+
+type metricsHistogram struct {
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newMetricsHistogram(buckets []float64) *metricsHistogram {
+	return &metricsHistogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *metricsHistogram) observe(value float64) {
+	for i, bound := range h.buckets {
+		if value <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += value
+	h.count++
+}
+
+type metricsSeries struct {
+	method   string
+	route    string
+	status   string
+	requests uint64
+	panics   uint64
+	duration *metricsHistogram
+	reqSize  *metricsHistogram
+	respSize *metricsHistogram
+}
+
+type metricsRegistry struct {
+	config    MetricsConfig
+	prefix    string
+	mutex     sync.Mutex
+	series    map[string]*metricsSeries
+	inFlight  atomic.Int64
+	startTime time.Time
+}
+
+var metrics *metricsRegistry
+var metricsServer *http.Server
+
+func initMetrics(config *MetricsConfig) error {
+	metrics = nil
+	metricsServer = nil
+	if config == nil {
+		return nil
+	}
+	registry := &metricsRegistry{
+		config:    *config,
+		series:    make(map[string]*metricsSeries),
+		startTime: time.Now(),
+	}
+	if registry.config.Path == "" {
+		registry.config.Path = "/metrics"
+	}
+	if len(registry.config.DurationBuckets) == 0 {
+		registry.config.DurationBuckets = defaultMetricsDurationBuckets
+	}
+	if len(registry.config.SizeBuckets) == 0 {
+		registry.config.SizeBuckets = defaultMetricsSizeBuckets
+	}
+	registry.config.DurationBuckets = slices.Sorted(slices.Values(registry.config.DurationBuckets))
+	registry.config.SizeBuckets = slices.Sorted(slices.Values(registry.config.SizeBuckets))
+	if config.Namespace != "" {
+		registry.prefix = config.Namespace + "_"
+	}
+	metrics = registry
+	if config.ListenAddress == "" {
+		return nil
+	}
+	listener, err := net.Listen("tcp", config.ListenAddress)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle(registry.config.Path, MetricsHandler())
+	server := &http.Server{Handler: mux}
+	metricsServer = server
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Logrus().Errorln("metrics server stopped:", err)
+		}
+	}()
+	return nil
+}
+
+// 在业务服务中注册指标路径
+func registerMetricsRoute(ginEngine *gin.Engine) {
+	if metrics == nil || metrics.config.ListenAddress != "" {
+		return
+	}
+	ginEngine.GET(metrics.config.Path, gin.WrapH(MetricsHandler()))
+}
+
+func stopMetrics(ctx context.Context) {
+	if metricsServer != nil {
+		_ = metricsServer.Shutdown(ctx)
+	}
+}
+
+// 指标采集中间件
+func metricsHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		registry := metrics
+		requestPath := ctx.Request.URL.Path
+		if registry == nil || (registry.config.ListenAddress == "" && requestPath == registry.config.Path) ||
+			accessLogExcluded(registry.config.ExcludePaths, requestPath) {
+			ctx.Next()
+			return
+		}
+		registry.inFlight.Add(1)
+		defer registry.inFlight.Add(-1)
+		start := time.Now()
+		writer := ctx.Writer
+		ctx.Next()
+
+		status := writer.Status()
+		if resolved, ok := ctx.Get(ginCtxKeyResolvedStatus); ok {
+			status = resolved.(int)
+		}
+		route := ctx.FullPath()
+		if route == "" {
+			route = metricsUnmatchedRoute
+		}
+		_, panicked := ctx.Get(ginCtxKeyPanic)
+		registry.observe(ctx.Request.Method, route, strconv.Itoa(status/100)+"xx", time.Since(start),
+			max(ctx.Request.ContentLength, 0), max(writer.Size(), 0), panicked)
+	}
+}
+
+func (m *metricsRegistry) observe(method, route, status string, duration time.Duration, reqSize int64, respSize int, panicked bool) {
+	key := method + "\xff" + route + "\xff" + status
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	series, ok := m.series[key]
+	if !ok {
+		series = &metricsSeries{
+			method:   method,
+			route:    route,
+			status:   status,
+			duration: newMetricsHistogram(m.config.DurationBuckets),
+			reqSize:  newMetricsHistogram(m.config.SizeBuckets),
+			respSize: newMetricsHistogram(m.config.SizeBuckets),
+		}
+		m.series[key] = series
+	}
+	series.requests++
+	if panicked {
+		series.panics++
+	}
+	series.duration.observe(duration.Seconds())
+	series.reqSize.observe(float64(reqSize))
+	series.respSize.observe(float64(respSize))
+}
+
+// MetricsHandler Prometheus文本格式的指标处理器 可用于自定义暴露方式 需配置GinConfig.Metrics
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		registry := metrics
+		if registry == nil {
+			http.NotFound(w, nil)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		writer := bufio.NewWriter(w)
+		registry.write(writer)
+		_ = writer.Flush()
+	})
+}
+
+func (m *metricsRegistry) write(w *bufio.Writer) {
+	m.mutex.Lock()
+	series := make([]*metricsSeries, 0, len(m.series))
+	for _, v := range m.series {
+		copied := *v
+		copied.duration = cloneMetricsHistogram(v.duration)
+		copied.reqSize = cloneMetricsHistogram(v.reqSize)
+		copied.respSize = cloneMetricsHistogram(v.respSize)
+		series = append(series, &copied)
+	}
+	m.mutex.Unlock()
+	slices.SortFunc(series, func(a, b *metricsSeries) int {
+		return strings.Compare(a.route+a.method+a.status, b.route+b.method+b.status)
+	})
+
+	name := m.prefix + "http_server_requests_total"
+	writeMetricsHeader(w, name, "counter", "Total number of HTTP requests.")
+	for _, s := range series {
+		writeMetricsSample(w, name, metricsLabels("method", s.method, "route", s.route, "status", s.status), float64(s.requests))
+	}
+	name = m.prefix + "http_server_request_duration_seconds"
+	writeMetricsHeader(w, name, "histogram", "HTTP request latency in seconds.")
+	for _, s := range series {
+		writeMetricsHistogram(w, name, metricsLabels("method", s.method, "route", s.route, "status", s.status), s.duration)
+	}
+	name = m.prefix + "http_server_request_size_bytes"
+	writeMetricsHeader(w, name, "histogram", "HTTP request body size in bytes.")
+	for _, s := range series {
+		writeMetricsHistogram(w, name, metricsLabels("method", s.method, "route", s.route, "status", s.status), s.reqSize)
+	}
+	name = m.prefix + "http_server_response_size_bytes"
+	writeMetricsHeader(w, name, "histogram", "HTTP response body size in bytes.")
+	for _, s := range series {
+		writeMetricsHistogram(w, name, metricsLabels("method", s.method, "route", s.route, "status", s.status), s.respSize)
+	}
+	name = m.prefix + "http_server_panics_total"
+	writeMetricsHeader(w, name, "counter", "Total number of panics recovered while handling HTTP requests.")
+	for _, s := range series {
+		if s.panics > 0 {
+			writeMetricsSample(w, name, metricsLabels("method", s.method, "route", s.route, "status", s.status), float64(s.panics))
+		}
+	}
+	name = m.prefix + "http_server_requests_in_flight"
+	writeMetricsHeader(w, name, "gauge", "Number of HTTP requests currently being handled.")
+	writeMetricsSample(w, name, "", float64(m.inFlight.Load()))
+
+	if !m.config.DisableRuntimeMetrics {
+		writeRuntimeMetrics(w, m.startTime)
+	}
+}
+
+func writeRuntimeMetrics(w *bufio.Writer, startTime time.Time) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	writeMetricsHeader(w, "go_info", "gauge", "Information about the Go environment.")
+	writeMetricsSample(w, "go_info", metricsLabels("version", runtime.Version()), 1)
+	gauges := []struct {
+		name  string
+		help  string
+		value float64
+	}{
+		{"go_goroutines", "Number of goroutines that currently exist.", float64(runtime.NumGoroutine())},
+		{"go_threads", "Number of OS threads created.", float64(metricsThreads())},
+		{"go_memstats_alloc_bytes", "Number of bytes allocated and still in use.", float64(stats.Alloc)},
+		{"go_memstats_heap_inuse_bytes", "Number of heap bytes that are in use.", float64(stats.HeapInuse)},
+		{"go_memstats_heap_objects", "Number of allocated objects.", float64(stats.HeapObjects)},
+		{"go_memstats_stack_inuse_bytes", "Number of bytes in use by the stack allocator.", float64(stats.StackInuse)},
+		{"go_memstats_sys_bytes", "Number of bytes obtained from system.", float64(stats.Sys)},
+		{"go_memstats_next_gc_bytes", "Number of heap bytes when next garbage collection will take place.", float64(stats.NextGC)},
+		{"go_memstats_last_gc_time_seconds", "Number of seconds since 1970 of last garbage collection.", float64(stats.LastGC) / 1e9},
+		{"process_start_time_seconds", "Start time of the process since unix epoch in seconds.", float64(startTime.UnixNano()) / 1e9},
+	}
+	for _, gauge := range gauges {
+		writeMetricsHeader(w, gauge.name, "gauge", gauge.help)
+		writeMetricsSample(w, gauge.name, "", gauge.value)
+	}
+	counters := []struct {
+		name  string
+		help  string
+		value float64
+	}{
+		{"go_memstats_alloc_bytes_total", "Total number of bytes allocated, even if freed.", float64(stats.TotalAlloc)},
+		{"go_memstats_mallocs_total", "Total number of mallocs.", float64(stats.Mallocs)},
+		{"go_memstats_frees_total", "Total number of frees.", float64(stats.Frees)},
+		{"go_gc_cycles_total", "Number of completed GC cycles.", float64(stats.NumGC)},
+		{"go_gc_pause_seconds_total", "Total GC pause time in seconds.", float64(stats.PauseTotalNs) / 1e9},
+	}
+	for _, counter := range counters {
+		writeMetricsHeader(w, counter.name, "counter", counter.help)
+		writeMetricsSample(w, counter.name, "", counter.value)
+	}
+}
+
+func metricsThreads() int {
+	n, _ := runtime.ThreadCreateProfile(nil)
+	return n
+}
+
+func cloneMetricsHistogram(h *metricsHistogram) *metricsHistogram {
+	copied := *h
+	copied.counts = slices.Clone(h.counts)
+	return &copied
+}
+
+func writeMetricsHeader(w *bufio.Writer, name, metricType, help string) {
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+func writeMetricsSample(w *bufio.Writer, name, labels string, value float64) {
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+	_, _ = fmt.Fprintf(w, "%s%s %s\n", name, labels, formatMetricsValue(value))
+}
+
+// 分桶计数在observe时已按上界累计
+func writeMetricsHistogram(w *bufio.Writer, name, labels string, h *metricsHistogram) {
+	separator := ""
+	if labels != "" {
+		separator = ","
+	}
+	for i, bound := range h.buckets {
+		writeMetricsSample(w, name+"_bucket", labels+separator+metricsLabels("le", formatMetricsValue(bound)), float64(h.counts[i]))
+	}
+	writeMetricsSample(w, name+"_bucket", labels+separator+metricsLabels("le", "+Inf"), float64(h.count))
+	writeMetricsSample(w, name+"_sum", labels, h.sum)
+	writeMetricsSample(w, name+"_count", labels, float64(h.count))
+}
+
+func metricsLabels(pairs ...string) string {
+	builder := strings.Builder{}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteString(pairs[i])
+		builder.WriteString(`="`)
+		builder.WriteString(metricsLabelEscaper.Replace(pairs[i+1]))
+		builder.WriteByte('"')
+	}
+	return builder.String()
+}
+
+var metricsLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatMetricsValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,253 @@
+package ginstarter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/acexy/golang-toolkit/logger"
+	"github.com/gin-gonic/gin"
+)
+
+/**
+Panic上报
+将非框架内部错误的panic异步交由PanicReporter处理 用于对接Sentry等异常收集服务
+相同堆栈指纹的panic在去重窗口内仅上报一次 并在下次上报时携带期间被抑制的次数
+*/
+
+// PanicReport Panic上报内容
+type PanicReport struct {
+	// 原始panic值
+	Value any
+	Error error
+	Stack string
+	// 堆栈指纹 相同代码位置触发的panic指纹相同
+	Fingerprint string
+	Time        time.Time
+	// 去重窗口内发生的次数 包含本次
+	Occurrences int
+
+	Method    string
+	Path      string
+	Route     string
+	ClientIP  string
+	Headers   map[string]string
+	Principal string
+	TraceId   string
+	RequestId string
+}
+
+// PanicReporter Panic上报处理器 在独立的协程中调用
+type PanicReporter interface {
+	ReportPanic(report *PanicReport)
+}
+
+// PanicReporterFunc 函数形式的PanicReporter
+type PanicReporterFunc func(report *PanicReport)
+
+func (f PanicReporterFunc) ReportPanic(report *PanicReport) {
+	f(report)
+}
+
+// PanicReportConfig Panic上报配置
+type PanicReportConfig struct {
+	Reporter PanicReporter
+	// 异步上报队列长度 队列已满时丢弃 默认100
+	QueueSize int
+	// 去重窗口 默认1分钟
+	DedupWindow time.Duration
+	// 每分钟最多上报次数 默认60
+	RateLimit int
+	// 额外需要脱敏的请求头 默认脱敏Authorization Cookie等认证相关请求头
+	RedactHeaders []string
+}
+
+var defaultPanicRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key", "X-Csrf-Token", "X-Signature"}
+
+type panicDedupEntry struct {
+	reportedAt  time.Time
+	occurrences int
+}
+
+type panicReporter struct {
+	config        PanicReportConfig
+	redactHeaders map[string]bool
+	queue         chan *PanicReport
+	done          chan struct{}
+
+	mutex       sync.Mutex
+	closed      bool
+	dedup       map[string]*panicDedupEntry
+	windowStart time.Time
+	windowCount int
+}
+
+var panicReporting atomic.Pointer[panicReporter]
+
+func initPanicReport(config *PanicReportConfig) {
+	panicReporting.Store(nil)
+	if config == nil || config.Reporter == nil {
+		return
+	}
+	reporter := &panicReporter{
+		config:        *config,
+		redactHeaders: make(map[string]bool),
+		dedup:         make(map[string]*panicDedupEntry),
+	}
+	if reporter.config.QueueSize <= 0 {
+		reporter.config.QueueSize = 100
+	}
+	if reporter.config.DedupWindow <= 0 {
+		reporter.config.DedupWindow = time.Minute
+	}
+	if reporter.config.RateLimit <= 0 {
+		reporter.config.RateLimit = 60
+	}
+	for _, header := range append(defaultPanicRedactHeaders, config.RedactHeaders...) {
+		reporter.redactHeaders[http.CanonicalHeaderKey(header)] = true
+	}
+	reporter.queue = make(chan *PanicReport, reporter.config.QueueSize)
+	reporter.done = make(chan struct{})
+	go reporter.run()
+	panicReporting.Store(reporter)
+}
+
+// 停止上报 等待队列中的上报完成
+func stopPanicReport(ctx context.Context) {
+	reporter := panicReporting.Swap(nil)
+	if reporter == nil {
+		return
+	}
+	reporter.mutex.Lock()
+	reporter.closed = true
+	close(reporter.queue)
+	reporter.mutex.Unlock()
+	select {
+	case <-reporter.done:
+	case <-ctx.Done():
+	}
+}
+
+func (p *panicReporter) run() {
+	defer close(p.done)
+	for report := range p.queue {
+		p.deliver(report)
+	}
+}
+
+func (p *panicReporter) deliver(report *PanicReport) {
+	defer func() {
+		if err := recover(); err != nil {
+			logger.Logrus().Errorln("panic reporter failed:", err)
+		}
+	}()
+	p.config.Reporter.ReportPanic(report)
+}
+
+// 记录panic 去重及限流后加入上报队列
+func reportPanic(ctx *gin.Context, value any, err error, stack string) {
+	reporter := panicReporting.Load()
+	if reporter == nil {
+		return
+	}
+	now := time.Now()
+	fingerprint := panicFingerprint(err, stack)
+	occurrences, ok := reporter.admit(fingerprint, now)
+	if !ok {
+		return
+	}
+	report := &PanicReport{
+		Value:       value,
+		Error:       err,
+		Stack:       stack,
+		Fingerprint: fingerprint,
+		Time:        now,
+		Occurrences: occurrences,
+		Method:      ctx.Request.Method,
+		Path:        ctx.Request.URL.Path,
+		Route:       ctx.FullPath(),
+		ClientIP:    trustedClientIP(ctx),
+		Headers:     make(map[string]string, len(ctx.Request.Header)),
+		TraceId:     requestTraceId(ctx),
+		RequestId:   RequestIdFromContext(ctx.Request.Context()),
+	}
+	for name, values := range ctx.Request.Header {
+		if reporter.redactHeaders[name] {
+			report.Headers[name] = "******"
+		} else {
+			report.Headers[name] = strings.Join(values, ", ")
+		}
+	}
+	if principal, ok := PrincipalFromContext(ctx.Request.Context()); ok && principal != nil {
+		report.Principal = principal.Subject()
+	}
+	reporter.enqueue(report)
+}
+
+func (p *panicReporter) enqueue(report *PanicReport) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.closed {
+		return
+	}
+	select {
+	case p.queue <- report:
+	default:
+		logger.Logrus().Warningln("panic report queue is full, report dropped fingerprint:", report.Fingerprint)
+	}
+}
+
+// 判断是否上报 返回去重窗口内的发生次数
+func (p *panicReporter) admit(fingerprint string, now time.Time) (int, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	entry, exists := p.dedup[fingerprint]
+	if exists && now.Sub(entry.reportedAt) < p.config.DedupWindow {
+		entry.occurrences++
+		return 0, false
+	}
+	if now.Sub(p.windowStart) >= time.Minute {
+		p.windowStart = now
+		p.windowCount = 0
+	}
+	if p.windowCount >= p.config.RateLimit {
+		if exists {
+			entry.occurrences++
+		}
+		return 0, false
+	}
+	p.windowCount++
+	occurrences := 1
+	if exists {
+		occurrences += entry.occurrences
+	}
+	p.dedup[fingerprint] = &panicDedupEntry{reportedAt: now}
+	if len(p.dedup) > 1024 {
+		for k, v := range p.dedup {
+			if now.Sub(v.reportedAt) >= p.config.DedupWindow {
+				delete(p.dedup, k)
+			}
+		}
+	}
+	return occurrences, true
+}
+
+// 基于错误类型及堆栈中的代码位置计算指纹 忽略协程编号及指令偏移
+func panicFingerprint(err error, stack string) string {
+	hash := sha256.New()
+	hash.Write([]byte(fmt.Sprintf("%T", err)))
+	for _, line := range strings.Split(stack, "\n") {
+		if !strings.HasPrefix(line, "\t") {
+			continue
+		}
+		location, _, _ := strings.Cut(strings.TrimSpace(line), " +0x")
+		hash.Write([]byte(location))
+	}
+	return hex.EncodeToString(hash.Sum(nil)[:8])
+}
@@ -0,0 +1,326 @@
+package ginstarter
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/acexy/golang-toolkit/logger"
+)
+
+/**
+限流拦截器
+支持令牌桶与滑动窗口算法 默认使用内存分片存储 可实现RateLimitStore接口使用Redis等外部存储
+*/
+
+type RateLimitAlgorithm int
+
+const (
+	// RateLimitTokenBucket 令牌桶 每Window补充Limit个令牌 桶容量为Burst 允许一定程度的突发请求
+	RateLimitTokenBucket RateLimitAlgorithm = iota
+	// RateLimitSlidingWindow 滑动窗口 任意Window时间内最多允许Limit个请求
+	RateLimitSlidingWindow
+)
+
+const rateLimitStoreShards = 32
+
+// RateLimitKeyFunc 限流维度 返回空字符串时不限流
+type RateLimitKeyFunc func(request *Request) string
+
+// RateLimitRule 限流规则
+type RateLimitRule struct {
+	Algorithm RateLimitAlgorithm
+	Limit     int
+	Window    time.Duration
+	Burst     int
+}
+
+// RateLimitResult 限流结果
+type RateLimitResult struct {
+	// 是否允许本次请求
+	Allowed bool
+	// 限流上限
+	Limit int
+	// 剩余可用请求数
+	Remaining int
+	// 配额完全恢复所需时间
+	Reset time.Duration
+	// 被限流时建议的重试等待时间
+	RetryAfter time.Duration
+}
+
+// RateLimitStore 限流数据存储 可实现该接口使用Redis等外部存储实现分布式限流
+type RateLimitStore interface {
+	// Take 为key消耗一次请求配额
+	Take(ctx context.Context, key string, rule RateLimitRule) (*RateLimitResult, error)
+}
+
+// RateLimitConfig 限流配置
+type RateLimitConfig struct {
+	// 限流算法 默认令牌桶
+	Algorithm RateLimitAlgorithm
+	// 窗口期内允许的请求数 必须大于0
+	Limit int
+	// 窗口期 默认1秒
+	Window time.Duration
+	// 令牌桶容量 默认等于Limit 仅令牌桶算法有效
+	Burst int
+
+	// 限流维度 默认按客户端IP 未配置GinConfig.TrustedProxies及TrustedPlatform时使用连接的远端地址
+	KeyFunc RateLimitKeyFunc
+	// 限流key前缀 多个限流拦截器共用外部存储时用于区分
+	KeyPrefix string
+	// 限流数据存储 默认使用内存分片存储
+	Store RateLimitStore
+
+	// 禁用RateLimit-*响应头
+	DisableHeaders bool
+	// 存储异常时拒绝请求 默认放行
+	FailClosed bool
+}
+
+// RateLimitKeyByIP 按客户端IP限流 仅信任来自受信任代理的X-Forwarded-For 防止伪造IP绕过限流
+func RateLimitKeyByIP() RateLimitKeyFunc {
+	return func(request *Request) string {
+		return "ip:" + trustedClientIP(request.ctx)
+	}
+}
+
+// RateLimitKeyByHeader 按请求头限流 请求头为空时不限流
+func RateLimitKeyByHeader(name string) RateLimitKeyFunc {
+	return func(request *Request) string {
+		value := request.GetHeader(name)
+		if value == "" {
+			return ""
+		}
+		return "header:" + value
+	}
+}
+
+// RateLimitKeyByPrincipal 按认证主体限流 未认证时按客户端IP限流
+func RateLimitKeyByPrincipal() RateLimitKeyFunc {
+	return func(request *Request) string {
+		if principal, ok := request.Principal(); ok && principal != nil {
+			return "principal:" + principal.Subject()
+		}
+		return "ip:" + trustedClientIP(request.ctx)
+	}
+}
+
+// RateLimitKeyByRoute 按注册路由限流 即该路由的所有请求共享配额
+func RateLimitKeyByRoute() RateLimitKeyFunc {
+	return func(request *Request) string {
+		return "route:" + request.HttpMethod() + " " + request.RouterFullPath()
+	}
+}
+
+// RateLimitInterceptor 限流拦截器 超出限制时响应StatusCodeExceededLimit Limit不大于0时panic
+// match 满足指定条件才执行
+func RateLimitInterceptor(config *RateLimitConfig, match ...func(request *Request) bool) PreInterceptor {
+	if config.Limit <= 0 {
+		panic("rate limit: Limit must be greater than 0, got " + strconv.Itoa(config.Limit))
+	}
+	rule := RateLimitRule{
+		Algorithm: config.Algorithm,
+		Limit:     config.Limit,
+		Window:    config.Window,
+		Burst:     config.Burst,
+	}
+	if rule.Window <= 0 {
+		rule.Window = time.Second
+	}
+	if rule.Burst <= 0 {
+		rule.Burst = rule.Limit
+	}
+	keyFunc := config.KeyFunc
+	if keyFunc == nil {
+		keyFunc = RateLimitKeyByIP()
+	}
+	store := config.Store
+	if store == nil {
+		store = NewMemoryRateLimitStore()
+	}
+	return func(request *Request) (Response, bool, bool) {
+		if len(match) > 0 {
+			if !match[0](request) {
+				return nil, true, true
+			}
+		}
+		key := keyFunc(request)
+		if key == "" {
+			return nil, true, true
+		}
+		result, err := store.Take(request.Context(), config.KeyPrefix+key, rule)
+		if err != nil {
+			logger.Logrus().Warningln("rate limit store error key:", key, err)
+			if config.FailClosed {
+				return RespHttpStatusCode(http.StatusServiceUnavailable), false, false
+			}
+			return nil, true, true
+		}
+		if result.Allowed {
+			if !config.DisableHeaders {
+				for _, header := range rateLimitHeaders(result) {
+					request.ctx.Header(header.name, header.value)
+				}
+			}
+			return nil, true, true
+		}
+		responseData := NewEmptyResponseData().SetStatusCode(http.StatusTooManyRequests)
+		if !config.DisableHeaders {
+			responseData.AddHeaders(rateLimitHeaders(result))
+		}
+		responseData.AddHeader("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+		return NewCommonResp().SetDataToResponse(responseData), false, false
+	}
+}
+
+func rateLimitHeaders(result *RateLimitResult) []*ResponseHeader {
+	return []*ResponseHeader{
+		NewHeader("RateLimit-Limit", strconv.Itoa(result.Limit)),
+		NewHeader("RateLimit-Remaining", strconv.Itoa(result.Remaining)),
+		NewHeader("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset))),
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
+
+// MemoryRateLimitStore 内存分片限流存储 仅适用于单实例部署
+type MemoryRateLimitStore struct {
+	shards [rateLimitStoreShards]*rateLimitShard
+}
+
+type rateLimitShard struct {
+	mutex     sync.Mutex
+	entries   map[string]*rateLimitEntry
+	lastSweep time.Time
+}
+
+type rateLimitEntry struct {
+	// 令牌桶
+	tokens float64
+	// 滑动窗口
+	windowStart   time.Time
+	currentCount  int
+	previousCount int
+
+	lastAccess time.Time
+}
+
+// NewMemoryRateLimitStore 创建内存分片限流存储
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	store := &MemoryRateLimitStore{}
+	for i := range store.shards {
+		store.shards[i] = &rateLimitShard{
+			entries:   make(map[string]*rateLimitEntry),
+			lastSweep: time.Now(),
+		}
+	}
+	return store
+}
+
+func (m *MemoryRateLimitStore) Take(_ context.Context, key string, rule RateLimitRule) (*RateLimitResult, error) {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(key))
+	shard := m.shards[hash.Sum32()%rateLimitStoreShards]
+	now := time.Now()
+
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+	shard.sweep(now, rule.Window)
+	entry, ok := shard.entries[key]
+	if !ok {
+		entry = &rateLimitEntry{
+			tokens:      float64(rule.Burst),
+			windowStart: now.Truncate(rule.Window),
+			lastAccess:  now,
+		}
+		shard.entries[key] = entry
+	}
+	var result *RateLimitResult
+	if rule.Algorithm == RateLimitSlidingWindow {
+		result = entry.takeSlidingWindow(now, rule)
+	} else {
+		result = entry.takeTokenBucket(now, rule)
+	}
+	entry.lastAccess = now
+	return result, nil
+}
+
+// 清理长时间未访问的限流数据
+func (s *rateLimitShard) sweep(now time.Time, window time.Duration) {
+	idle := window * 2
+	if idle < time.Minute {
+		idle = time.Minute
+	}
+	if now.Sub(s.lastSweep) < idle {
+		return
+	}
+	for key, entry := range s.entries {
+		if now.Sub(entry.lastAccess) > idle {
+			delete(s.entries, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+func (e *rateLimitEntry) takeTokenBucket(now time.Time, rule RateLimitRule) *RateLimitResult {
+	rate := float64(rule.Limit) / rule.Window.Seconds()
+	capacity := float64(rule.Burst)
+	e.tokens = math.Min(capacity, e.tokens+now.Sub(e.lastAccess).Seconds()*rate)
+	result := &RateLimitResult{Limit: rule.Burst}
+	if rate <= 0 {
+		return result
+	}
+	if e.tokens >= 1 {
+		e.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - e.tokens) / rate * float64(time.Second))
+	}
+	result.Remaining = int(e.tokens)
+	result.Reset = time.Duration((capacity - e.tokens) / rate * float64(time.Second))
+	return result
+}
+
+func (e *rateLimitEntry) takeSlidingWindow(now time.Time, rule RateLimitRule) *RateLimitResult {
+	windowStart := now.Truncate(rule.Window)
+	if !windowStart.Equal(e.windowStart) {
+		if windowStart.Sub(e.windowStart) == rule.Window {
+			e.previousCount = e.currentCount
+		} else {
+			e.previousCount = 0
+		}
+		e.currentCount = 0
+		e.windowStart = windowStart
+	}
+	elapsed := now.Sub(windowStart)
+	weight := 1 - float64(elapsed)/float64(rule.Window)
+	estimated := float64(e.previousCount)*weight + float64(e.currentCount)
+	result := &RateLimitResult{
+		Limit: rule.Limit,
+		Reset: rule.Window - elapsed,
+	}
+	if estimated+1 <= float64(rule.Limit) {
+		e.currentCount++
+		estimated++
+		result.Allowed = true
+	} else if e.currentCount >= rule.Limit || e.previousCount == 0 {
+		result.RetryAfter = rule.Window - elapsed
+	} else {
+		// 等待上一窗口的权重衰减至可以容纳本次请求
+		wait := float64(rule.Window)*(1-float64(rule.Limit-1-e.currentCount)/float64(e.previousCount)) - float64(elapsed)
+		result.RetryAfter = time.Duration(math.Max(wait, 0))
+	}
+	result.Remaining = int(math.Max(0, math.Floor(float64(rule.Limit)-estimated)))
+	return result
+}
@@ -1,7 +1,10 @@
 package ginstarter
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"path/filepath"
@@ -18,10 +21,48 @@ type Request struct {
 }
 
 // RawGinContext 获取原始Gin上下文
+// 引擎启用了ContextWithFallback 将其作为context.Context使用时 Deadline Done Err Value均回退至Request.Context()
 func (r *Request) RawGinContext() *gin.Context {
 	return r.ctx
 }
 
+// Context 获取请求上下文 客户端断开连接、请求超时或服务停止时将被取消
+// 携带TraceId及认证主体 可直接传递给数据库、http客户端等下游调用
+func (r *Request) Context() context.Context {
+	requestTraceId(r.ctx)
+	return r.ctx.Request.Context()
+}
+
+// SetContext 替换请求上下文 新的上下文应派生自Context()
+func (r *Request) SetContext(ctx context.Context) {
+	r.ctx.Request = r.ctx.Request.WithContext(ctx)
+}
+
+// TraceId 获取当前请求的TraceId 启用TraceIdResponse时与响应头Trace-Id一致
+func (r *Request) TraceId() string {
+	return requestTraceId(r.ctx)
+}
+
+// SetTraceId 设置当前请求的TraceId
+func (r *Request) SetTraceId(traceId string) {
+	contextKeyTraceId.Set(r, traceId)
+}
+
+// Principal 获取当前请求的认证主体
+func (r *Request) Principal() (Principal, bool) {
+	return contextKeyPrincipal.Get(r)
+}
+
+// SetPrincipal 设置当前请求的认证主体 通常由认证拦截器调用
+func (r *Request) SetPrincipal(principal Principal) {
+	contextKeyPrincipal.Set(r, principal)
+}
+
+// Session 获取当前请求的会话 需配置GinConfig.SessionConfig
+func (r *Request) Session() *Session {
+	return requestSession(r.ctx)
+}
+
 // HttpMethod 获取请求方法
 func (r *Request) HttpMethod() string {
 	return r.ctx.Request.Method
@@ -57,6 +98,22 @@ func (r *Request) RequestIP() string {
 	return r.ctx.ClientIP()
 }
 
+// Locale 获取当前请求的语言 未启用国际化时返回空
+func (r *Request) Locale() string {
+	return requestLocale(r.ctx)
+}
+
+// StatusMessage 获取当前请求语言的状态信息
+func (r *Request) StatusMessage(statusCode StatusCode) StatusMessage {
+	return localeStatusMessage(r.ctx, statusCode)
+}
+
+// BizErrorMessage 获取当前请求语言的业务错误信息 params 替换信息中的{0} {1}占位符
+func (r *Request) BizErrorMessage(bizErrorCode BizErrorCode, params ...string) BizErrorMessage {
+	message, _ := localeBizErrorMessage(r.ctx, bizErrorCode, params...)
+	return message
+}
+
 // --------------- path 路径参数
 
 // GetPathParam 获取path路径参数 /:id
@@ -236,9 +293,14 @@ func (r *Request) MustBindBodyForm(object any) {
 	}
 }
 
-// GetRawBodyData 将请求body以字节数据返回
+// GetRawBodyData 将请求body以字节数据返回 读取后body仍可被再次读取及绑定
 func (r *Request) GetRawBodyData() ([]byte, error) {
-	return r.ctx.GetRawData()
+	data, err := r.ctx.GetRawData()
+	if err != nil {
+		return nil, err
+	}
+	r.ctx.Request.Body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
 }
 
 // MustBindBodyAuto 将请求body数据绑定到结构体中 自动识别
@@ -409,11 +471,15 @@ func (r *Request) MustGetCookie(name string) string {
 }
 
 // SetValue 向gin上下文绑定数据
+//
+// Deprecated: 使用类型安全的ContextKey替代 ContextKey绑定的数据可在Request.Context()中获取
 func (r *Request) SetValue(key string, value interface{}) {
 	r.ctx.Set(key, value)
 }
 
 // GetValue 从gin上下文获取数据
+//
+// Deprecated: 使用类型安全的ContextKey替代
 func (r *Request) GetValue(key string) (interface{}, bool) {
 	return r.ctx.Get(key)
 }
@@ -0,0 +1,107 @@
+package ginstarter
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+/**
+请求ID
+接受调用方传入的请求ID 无效或不存在时生成 在所有中间件之前执行
+请求ID在处理前写入响应头 因此panic及BadHttpCodeResolver的响应同样携带
+*/
+
+var contextKeyRequestId = NewContextKey[string]("requestId")
+
+// RequestIdConfig 请求ID配置
+type RequestIdConfig struct {
+	// 请求及响应的请求头名称 默认X-Request-Id
+	HeaderName string
+	// 忽略调用方传入的请求ID 始终生成新的请求ID
+	IgnoreInbound bool
+	// 请求ID生成器 默认生成32位十六进制字符串
+	Generator func() string
+	// 校验调用方传入的请求ID 默认允许1-128位字母 数字及-_.:
+	Validator func(requestId string) bool
+	// 将请求ID作为TraceId 用于Trace-Id响应头及日志 启用链路追踪时无效
+	UseAsTraceId bool
+}
+
+var requestIdConfig *RequestIdConfig
+
+func initRequestId(config *RequestIdConfig) {
+	requestIdConfig = nil
+	if config == nil {
+		return
+	}
+	c := *config
+	if c.HeaderName == "" {
+		c.HeaderName = "X-Request-Id"
+	}
+	if c.Generator == nil {
+		c.Generator = newRequestId
+	}
+	if c.Validator == nil {
+		c.Validator = validRequestId
+	}
+	requestIdConfig = &c
+}
+
+// RequestId 获取当前请求的请求ID 需配置GinConfig.RequestId
+func (r *Request) RequestId() string {
+	requestId, _ := contextKeyRequestId.Get(r)
+	return requestId
+}
+
+// RequestIdFromContext 从派生自请求上下文的context.Context中获取请求ID
+func RequestIdFromContext(ctx context.Context) string {
+	requestId, _ := contextKeyRequestId.Value(ctx)
+	return requestId
+}
+
+// 请求ID中间件
+func requestIdHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		config := requestIdConfig
+		if config == nil {
+			return
+		}
+		var requestId string
+		if !config.IgnoreInbound {
+			if inbound := ctx.GetHeader(config.HeaderName); inbound != "" && config.Validator(inbound) {
+				requestId = inbound
+			}
+		}
+		if requestId == "" {
+			requestId = config.Generator()
+		}
+		requestContext := context.WithValue(ctx.Request.Context(), contextKeyRequestId, requestId)
+		if config.UseAsTraceId && tracing == nil {
+			requestContext = context.WithValue(requestContext, contextKeyTraceId, requestId)
+		}
+		ctx.Request = ctx.Request.WithContext(requestContext)
+		ctx.Header(config.HeaderName, requestId)
+	}
+}
+
+func newRequestId() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+func validRequestId(requestId string) bool {
+	if len(requestId) > 128 {
+		return false
+	}
+	for i := 0; i < len(requestId); i++ {
+		c := requestId[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.' || c == ':') {
+			return false
+		}
+	}
+	return requestId != ""
+}
@@ -41,7 +41,7 @@ func httpResponse(context *gin.Context, response Response) {
 
 	// 是否启用traceId响应
 	if ginConfig.TraceIdResponse != nil {
-		context.Header("Trace-Id", ginConfig.TraceIdResponse())
+		context.Header(traceIdHeaderName(), requestTraceId(context))
 	}
 
 	responseData := response.Data()
@@ -49,6 +49,8 @@ func httpResponse(context *gin.Context, response Response) {
 		return
 	}
 
+	context.Set(ginCtxKeyBypassResolver, responseData.bypassResolver)
+
 	contentType := responseData.contentType
 	if contentType == "" {
 		contentType = gin.MIMEJSON
@@ -74,6 +76,9 @@ func httpResponse(context *gin.Context, response Response) {
 	}
 
 	data := responseData.data
+	if localized, ok := localeRestData(context, response); ok {
+		data = localized
+	}
 	writer := context.Writer
 	if w, ok := writer.(*responseRewriter); ok {
 		w.Rest() // 重置响应体
@@ -127,6 +132,8 @@ type ResponseData struct {
 	headers []*ResponseHeader
 	// 响应Cookie
 	cookies []*ResponseCookie
+	// 非200状态码不由BadHttpCodeResolver改写
+	bypassResolver bool
 }
 
 // ResponseHeader 响应头
@@ -245,6 +252,8 @@ func (r *ResponseData) RawBody() []byte {
 // restResp 默认的Rest响应结构体
 type restResp struct {
 	responseData *ResponseData
+	// 使用默认状态信息的Rest结构 启用国际化时按请求语言替换状态信息
+	localeRest *RestRespStruct
 }
 
 func (r *restResp) Data() *ResponseData {
@@ -290,6 +299,32 @@ func (r *restResp) ToResponse() Response {
 	return r
 }
 
+// 创建Rest响应 defaultMessage为true时状态信息按请求语言本地化
+func newLocaleRestResp(dataRest *RestRespStruct, defaultMessage bool) Response {
+	resp := NewRespRest()
+	if defaultMessage {
+		resp.localeRest = dataRest
+	}
+	return resp.SetDataResponse(dataRest)
+}
+
+// 按请求语言替换默认状态信息后的响应体 未启用国际化时返回false 不修改可能被复用的响应
+func localeRestData(ctx *gin.Context, response Response) ([]byte, bool) {
+	resp, ok := response.(*restResp)
+	if !ok || resp.localeRest == nil || resp.localeRest.Status == nil || universalTranslator == nil {
+		return nil, false
+	}
+	status := *resp.localeRest.Status
+	status.StatusMessage = localeStatusMessage(ctx, status.StatusCode)
+	dataRest := *resp.localeRest
+	dataRest.Status = &status
+	data, err := ginConfig.ResponseDataStructDecoder.Decode(&dataRest)
+	if err != nil {
+		panic(err)
+	}
+	return data, true
+}
+
 // RespRestRaw 响应标准格式的Rest原始数据
 func RespRestRaw(dataRest *RestRespStruct) Response {
 	return NewRespRest().SetDataResponse(dataRest)
@@ -297,32 +332,32 @@ func RespRestRaw(dataRest *RestRespStruct) Response {
 
 // RespRestSuccess 响应标准格式的Rest成功数据
 func RespRestSuccess(data ...any) Response {
-	return NewRespRest().SetDataResponse(NewRestSuccess(data...))
+	return newLocaleRestResp(NewRestSuccess(data...), true)
 }
 
 // RespRestException 响应标准格式的Rest系统异常错误
 func RespRestException(statusMessage ...string) Response {
-	return NewRespRest().SetDataResponse(NewRestException(statusMessage...))
+	return newLocaleRestResp(NewRestException(statusMessage...), len(statusMessage) == 0)
 }
 
 // RespRestBadParameters 响应标准格式的Rest参数错误
 func RespRestBadParameters(statusMessage ...string) Response {
-	return NewRespRest().SetDataResponse(NewRestBadParameters(statusMessage...))
+	return newLocaleRestResp(NewRestBadParameters(statusMessage...), len(statusMessage) == 0)
 }
 
 // RespRestUnAuthorized 响应标准格式的Rest未授权错误
 func RespRestUnAuthorized(statusMessage ...string) Response {
-	return NewRespRest().SetDataResponse(NewRestUnauthorized(statusMessage...))
+	return newLocaleRestResp(NewRestUnauthorized(statusMessage...), len(statusMessage) == 0)
 }
 
 // RespRestStatusError 响应标准格式的Rest状态错误
 func RespRestStatusError(statusCode StatusCode, statusMessage ...StatusMessage) Response {
-	return NewRespRest().SetDataResponse(NewRestStatusError(statusCode, statusMessage...))
+	return newLocaleRestResp(NewRestStatusError(statusCode, statusMessage...), len(statusMessage) == 0 || statusMessage[0] == "")
 }
 
 // RespRestBizError 响应标准格式的Rest业务错误
 func RespRestBizError(bizErrorCode BizErrorCode, bizErrorMessage BizErrorMessage) Response {
-	return NewRespRest().SetDataResponse(NewRestBizError(bizErrorCode, bizErrorMessage))
+	return newLocaleRestResp(NewRestBizError(bizErrorCode, bizErrorMessage), true)
 }
 
 // commonResp 普通响应
@@ -1,6 +1,8 @@
 package ginstarter
 
 import (
+	"time"
+
 	"github.com/acexy/golang-toolkit/util/coll"
 	"github.com/gin-gonic/gin"
 )
@@ -13,6 +15,22 @@ type RouterInfo struct {
 	PreInterceptors []PreInterceptor
 	// 该Router下的后置拦截器
 	PostInterceptors []PostInterceptor
+
+	// 该Router下的请求超时时间 覆盖全局配置 负数表示禁用
+	Timeout time.Duration
+
+	// 该Router下的并发限制 所有路由共享并发额度
+	ConcurrencyLimit *ConcurrencyLimitConfig
+	// 该Router下路由的请求优先级 过载时优先削减低优先级请求
+	Priority RequestPriority
+	// 该Router下路由的访问授权要求
+	Authorization *Authorization
+	// 该Router下的跨域配置 覆盖全局配置
+	Cors *CorsConfig
+	// 该Router下的IP访问控制 覆盖全局白名单 全局黑名单仍然生效
+	IPFilter *IPFilter
+	// 该Router下的CSRF防护 先于PreInterceptors执行 同步令牌模式未配置GinConfig.SessionConfig时启动失败
+	Csrf *CsrfConfig
 }
 
 type Router interface {
@@ -22,11 +40,28 @@ type Router interface {
 	Handlers(router *RouterWrapper)
 }
 
-func registerRouter(ginEngine *gin.Engine, routers []Router) {
+func registerRouter(ginEngine *gin.Engine, routers []Router) error {
 	for _, router := range routers {
 		routerInfo := router.Info()
 		group := ginEngine.Group(routerInfo.GroupPath)
 
+		if routerInfo.IPFilter != nil {
+			registerRouterIPFilter(routerInfo.GroupPath, routerInfo.IPFilter)
+		}
+		if routerInfo.Cors != nil {
+			if err := registerRouterCors(routerInfo.GroupPath, routerInfo.Cors); err != nil {
+				return err
+			}
+		}
+
+		if routerInfo.Csrf != nil {
+			csrf, err := registerRouterCsrf(routerInfo.Csrf)
+			if err != nil {
+				return err
+			}
+			routerInfo.PreInterceptors = append([]PreInterceptor{csrf}, routerInfo.PreInterceptors...)
+		}
+
 		routerInfo.PreInterceptors = coll.SliceFilter(routerInfo.PreInterceptors, func(p PreInterceptor) bool {
 			return p != nil
 		})
@@ -34,6 +69,10 @@ func registerRouter(ginEngine *gin.Engine, routers []Router) {
 			return p != nil
 		})
 
+		if routerInfo.ConcurrencyLimit != nil {
+			group.Use(newConcurrencyLimiter(routerInfo.ConcurrencyLimit).handler())
+		}
+
 		if len(routerInfo.PreInterceptors) != 0 || len(routerInfo.PostInterceptors) != 0 {
 			if len(routerInfo.PreInterceptors) > 0 {
 				group.Use(func(ctx *gin.Context) {
@@ -86,6 +125,15 @@ func registerRouter(ginEngine *gin.Engine, routers []Router) {
 				}
 			})
 		}
-		router.Handlers(&RouterWrapper{routerGroup: group})
+		wrapper := &RouterWrapper{
+			routerGroup: group,
+			timeout:     resolveTimeout(routerInfo.Timeout, ginConfig.RequestTimeout),
+			priority:    routerInfo.Priority,
+		}
+		if routerInfo.Authorization != nil {
+			wrapper.authorizations = []*Authorization{routerInfo.Authorization}
+		}
+		router.Handlers(wrapper)
 	}
+	return nil
 }
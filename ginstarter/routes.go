@@ -0,0 +1,116 @@
+package ginstarter
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/acexy/golang-toolkit/logger"
+)
+
+// RouteInfo 通过RouterWrapper注册的路由信息
+type RouteInfo struct {
+	Method string
+	Path   string
+	// 请求超时时间
+	Timeout time.Duration
+	// 请求优先级
+	Priority RequestPriority
+	// 访问授权要求 需全部满足
+	Authorizations []*Authorization
+}
+
+func (r *RouteInfo) String() string {
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("%-7s %s", r.Method, r.Path))
+	if r.Timeout > 0 {
+		builder.WriteString(" timeout=" + r.Timeout.String())
+	}
+	if r.Priority != RequestPriorityNormal {
+		builder.WriteString(fmt.Sprintf(" priority=%d", r.Priority))
+	}
+	for _, authorization := range r.Authorizations {
+		builder.WriteString(" " + authorization.String())
+	}
+	return builder.String()
+}
+
+var routeTable []*RouteInfo
+var routeIndex map[string]*RouteInfo
+
+// Routes 获取已注册的路由表
+func Routes() []*RouteInfo {
+	routes := make([]*RouteInfo, len(routeTable))
+	copy(routes, routeTable)
+	return routes
+}
+
+func resetRouteTable() {
+	routeTable = nil
+	routeIndex = make(map[string]*RouteInfo)
+}
+
+// 记录路由信息 调试模式下打印路由表
+func registerRoute(methods []string, fullPath string, wrapper *RouterWrapper) {
+	for _, method := range methods {
+		route := &RouteInfo{
+			Method:         method,
+			Path:           fullPath,
+			Timeout:        wrapper.timeout,
+			Priority:       wrapper.priority,
+			Authorizations: wrapper.authorizations,
+		}
+		routeTable = append(routeTable, route)
+		routeIndex[method+" "+fullPath] = route
+		if ginConfig.DebugModule {
+			logger.Logrus().Debugln("[route]", route.String())
+		}
+	}
+}
+
+func lookupRoute(method, fullPath string) *RouteInfo {
+	return routeIndex[method+" "+fullPath]
+}
+
+// 与gin路由分组的路径拼接规则一致
+func joinRoutePath(basePath, relativePath string) string {
+	if relativePath == "" {
+		return basePath
+	}
+	finalPath := path.Join(basePath, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(finalPath, "/") {
+		return finalPath + "/"
+	}
+	return finalPath
+}
+
+// Router级别的配置 按照GroupPath匹配请求路径 用于需要同样作用于404及405处理的全局中间件
+type routerScoped[T any] struct {
+	prefix string
+	value  T
+}
+
+func newRouterScoped[T any](groupPath string, value T) *routerScoped[T] {
+	return &routerScoped[T]{
+		prefix: strings.TrimSuffix(joinRoutePath("/", groupPath), "/"),
+		value:  value,
+	}
+}
+
+// 匹配GroupPath最长的Router配置
+func matchRouterScoped[T any](scoped []*routerScoped[T], requestPath string) (T, bool) {
+	var matched *routerScoped[T]
+	for _, v := range scoped {
+		if requestPath == v.prefix || strings.HasPrefix(requestPath, v.prefix+"/") {
+			if matched == nil || len(v.prefix) > len(matched.prefix) {
+				matched = v
+			}
+		}
+	}
+	if matched == nil {
+		var zero T
+		return zero, false
+	}
+	return matched.value, true
+}
@@ -0,0 +1,547 @@
+package ginstarter
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/acexy/golang-toolkit/logger"
+	"github.com/gin-gonic/gin"
+)
+
+/**
+会话管理
+未指定SessionStore时会话数据经签名(或加密)后全部保存在Cookie中
+指定SessionStore时Cookie中仅保存签名后的会话ID 会话数据保存在服务端
+*/
+
+const sessionFlashKey = "_flash"
+const sessionCookieMaxSize = 4096
+
+// SessionStore 服务端会话存储 可实现该接口使用Redis等外部存储
+type SessionStore interface {
+	// Load 加载会话数据 不存在时返回nil
+	Load(ctx context.Context, id string) ([]byte, error)
+	// Save 保存会话数据 ttl后过期
+	Save(ctx context.Context, id string, data []byte, ttl time.Duration) error
+	// Delete 删除会话数据
+	Delete(ctx context.Context, id string) error
+}
+
+// SessionConfig 会话配置
+type SessionConfig struct {
+	// 服务端会话存储 为nil时会话数据保存在Cookie中
+	Store SessionStore
+	// 签名密钥 用于Cookie防篡改 未设置EncryptionKey时使用Cookie存储必须设置
+	SecretKey []byte
+	// 加密密钥 16/24/32字节 设置后Cookie存储的会话数据将使用AES-GCM加密
+	EncryptionKey []byte
+
+	// Cookie名称 默认session
+	CookieName string
+	// Cookie路径 默认/
+	CookiePath   string
+	CookieDomain string
+	// 仅在https下发送Cookie
+	CookieSecure bool
+	// 默认http.SameSiteLaxMode
+	CookieSameSite http.SameSite
+	// 允许js读取Cookie 默认HttpOnly
+	DisableHttpOnly bool
+
+	// 空闲过期时间 超过该时间未访问会话将失效 默认30分钟
+	IdleTimeout time.Duration
+	// 绝对过期时间 自会话创建起超过该时间会话将失效 默认24小时
+	AbsoluteTimeout time.Duration
+}
+
+var sessionConfig *SessionConfig
+var sessionAead cipher.AEAD
+
+type sessionData struct {
+	Id         string         `json:"i"`
+	Values     map[string]any `json:"v"`
+	CreatedAt  int64          `json:"c"`
+	LastAccess int64          `json:"a"`
+}
+
+// Session 请求会话
+type Session struct {
+	data      sessionData
+	oldId     string
+	isNew     bool
+	modified  bool
+	destroyed bool
+}
+
+// 单次请求的会话状态
+type sessionState struct {
+	mutex     sync.Mutex
+	request   *http.Request
+	session   *Session
+	committed bool
+}
+
+func initSession(config *SessionConfig) error {
+	sessionConfig = config
+	if config == nil {
+		return nil
+	}
+	if config.CookieName == "" {
+		config.CookieName = "session"
+	}
+	if config.CookiePath == "" {
+		config.CookiePath = "/"
+	}
+	if config.CookieSameSite == 0 {
+		config.CookieSameSite = http.SameSiteLaxMode
+	}
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = 30 * time.Minute
+	}
+	if config.AbsoluteTimeout <= 0 {
+		config.AbsoluteTimeout = 24 * time.Hour
+	}
+	sessionAead = nil
+	if len(config.EncryptionKey) > 0 {
+		block, err := aes.NewCipher(config.EncryptionKey)
+		if err != nil {
+			return err
+		}
+		if sessionAead, err = cipher.NewGCM(block); err != nil {
+			return err
+		}
+	} else if config.Store == nil && len(config.SecretKey) == 0 {
+		return errors.New("session cookie store requires SecretKey or EncryptionKey")
+	}
+	return nil
+}
+
+// 会话中间件 在响应写入前保存会话
+func sessionHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		state := &sessionState{request: ctx.Request}
+		ctx.Set(ginCtxKeySession, state)
+		ctx.Writer = &sessionWriter{ResponseWriter: ctx.Writer, state: state}
+		ctx.Next()
+		state.commit(ctx.Writer)
+	}
+}
+
+// 获取当前请求的会话 首次调用时加载
+func requestSession(ctx *gin.Context) *Session {
+	v, ok := ctx.Get(ginCtxKeySession)
+	if !ok {
+		panic(errors.New("session not enabled, set GinConfig.SessionConfig"))
+	}
+	state := v.(*sessionState)
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if state.session == nil {
+		state.session = loadSession(ctx.Request)
+	}
+	return state.session
+}
+
+func loadSession(request *http.Request) *Session {
+	now := time.Now()
+	if cookie, err := request.Cookie(sessionConfig.CookieName); err == nil && cookie.Value != "" {
+		data, err := decodeSessionCookie(request.Context(), cookie.Value)
+		if err != nil {
+			logger.Logrus().Debugln("invalid session cookie:", err)
+		} else if data != nil {
+			if now.Sub(time.Unix(data.LastAccess, 0)) <= sessionConfig.IdleTimeout &&
+				now.Sub(time.Unix(data.CreatedAt, 0)) <= sessionConfig.AbsoluteTimeout {
+				if data.Values == nil {
+					data.Values = make(map[string]any)
+				}
+				data.LastAccess = now.Unix()
+				return &Session{data: *data}
+			}
+			// 会话已过期 清除服务端数据
+			if sessionConfig.Store != nil {
+				_ = sessionConfig.Store.Delete(request.Context(), data.Id)
+			}
+		}
+	}
+	return &Session{
+		data: sessionData{
+			Id:         newSessionId(),
+			Values:     make(map[string]any),
+			CreatedAt:  now.Unix(),
+			LastAccess: now.Unix(),
+		},
+		isNew: true,
+	}
+}
+
+// 保存会话并写入Cookie 仅执行一次
+func (s *sessionState) commit(writer http.ResponseWriter) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.committed || s.session == nil {
+		return
+	}
+	s.committed = true
+	session := s.session
+	ctx := s.request.Context()
+	store := sessionConfig.Store
+	if session.destroyed {
+		if store != nil {
+			if err := store.Delete(ctx, session.data.Id); err != nil {
+				logger.Logrus().Errorln("delete session error:", err)
+			}
+		}
+		http.SetCookie(writer, sessionCookie("", -1))
+		return
+	}
+	if session.isNew && !session.modified {
+		return
+	}
+	if store != nil && session.oldId != "" {
+		if err := store.Delete(ctx, session.oldId); err != nil {
+			logger.Logrus().Errorln("delete rotated session error:", err)
+		}
+	}
+	payload, err := json.Marshal(session.data)
+	if err != nil {
+		logger.Logrus().Errorln("encode session error:", err)
+		return
+	}
+	var value string
+	if store != nil {
+		ttl := min(sessionConfig.IdleTimeout, sessionConfig.AbsoluteTimeout-time.Since(time.Unix(session.data.CreatedAt, 0)))
+		if err = store.Save(ctx, session.data.Id, payload, ttl); err != nil {
+			logger.Logrus().Errorln("save session error:", err)
+			return
+		}
+		value = signSessionValue(session.data.Id)
+	} else {
+		value = encodeSessionPayload(payload)
+		if len(value) > sessionCookieMaxSize {
+			logger.Logrus().Warningln("session cookie exceeds", sessionCookieMaxSize, "bytes, consider using SessionStore")
+		}
+	}
+	http.SetCookie(writer, sessionCookie(value, 0))
+}
+
+func sessionCookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     sessionConfig.CookieName,
+		Value:    value,
+		Path:     sessionConfig.CookiePath,
+		Domain:   sessionConfig.CookieDomain,
+		MaxAge:   maxAge,
+		Secure:   sessionConfig.CookieSecure,
+		HttpOnly: !sessionConfig.DisableHttpOnly,
+		SameSite: sessionConfig.CookieSameSite,
+	}
+}
+
+func decodeSessionCookie(ctx context.Context, value string) (*sessionData, error) {
+	var payload []byte
+	if sessionConfig.Store != nil {
+		id, ok := verifySessionValue(value)
+		if !ok {
+			return nil, errors.New("session id signature mismatch")
+		}
+		var err error
+		if payload, err = sessionConfig.Store.Load(ctx, id); err != nil || payload == nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if payload, err = decodeSessionPayload(value); err != nil {
+			return nil, err
+		}
+	}
+	data := &sessionData{}
+	if err := json.Unmarshal(payload, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Cookie存储 加密或签名会话数据
+func encodeSessionPayload(payload []byte) string {
+	if sessionAead != nil {
+		nonce := make([]byte, sessionAead.NonceSize())
+		_, _ = rand.Read(nonce)
+		sealed := sessionAead.Seal(nonce, nonce, payload, []byte(sessionConfig.CookieName))
+		return base64.RawURLEncoding.EncodeToString(sealed)
+	}
+	return signSessionValue(base64.RawURLEncoding.EncodeToString(payload))
+}
+
+func decodeSessionPayload(value string) ([]byte, error) {
+	if sessionAead != nil {
+		sealed, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil || len(sealed) < sessionAead.NonceSize() {
+			return nil, errors.New("malformed session cookie")
+		}
+		nonceSize := sessionAead.NonceSize()
+		return sessionAead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(sessionConfig.CookieName))
+	}
+	encoded, ok := verifySessionValue(value)
+	if !ok {
+		return nil, errors.New("session cookie signature mismatch")
+	}
+	return base64.RawURLEncoding.DecodeString(encoded)
+}
+
+// 为Cookie值追加签名 未设置SecretKey时不签名
+func signSessionValue(value string) string {
+	if len(sessionConfig.SecretKey) == 0 {
+		return value
+	}
+	return value + "." + sessionSignature(value)
+}
+
+func verifySessionValue(signed string) (string, bool) {
+	if len(sessionConfig.SecretKey) == 0 {
+		return signed, true
+	}
+	index := strings.LastIndex(signed, ".")
+	if index == -1 {
+		return "", false
+	}
+	value := signed[:index]
+	if !hmac.Equal([]byte(signed[index+1:]), []byte(sessionSignature(value))) {
+		return "", false
+	}
+	return value, true
+}
+
+func sessionSignature(value string) string {
+	mac := hmac.New(sha256.New, sessionConfig.SecretKey)
+	mac.Write([]byte(sessionConfig.CookieName + "|" + value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newSessionId() string {
+	id := make([]byte, 32)
+	_, _ = rand.Read(id)
+	return base64.RawURLEncoding.EncodeToString(id)
+}
+
+// ID 会话ID
+func (s *Session) ID() string {
+	return s.data.Id
+}
+
+// IsNew 是否为本次请求新创建的会话
+func (s *Session) IsNew() bool {
+	return s.isNew
+}
+
+// CreatedAt 会话创建时间
+func (s *Session) CreatedAt() time.Time {
+	return time.Unix(s.data.CreatedAt, 0)
+}
+
+// Get 获取会话数据 通过服务端存储或Cookie恢复的数据为JSON解码后的类型 推荐使用SessionValue获取指定类型的数据
+func (s *Session) Get(key string) (any, bool) {
+	v, ok := s.data.Values[key]
+	return v, ok
+}
+
+// GetString 获取字符串类型的会话数据
+func (s *Session) GetString(key string) string {
+	v, _ := sessionValue[string](s, key)
+	return v
+}
+
+// GetInt 获取整数类型的会话数据
+func (s *Session) GetInt(key string) int {
+	v, _ := sessionValue[int](s, key)
+	return v
+}
+
+// GetBool 获取布尔类型的会话数据
+func (s *Session) GetBool(key string) bool {
+	v, _ := sessionValue[bool](s, key)
+	return v
+}
+
+// Set 设置会话数据 值需支持JSON序列化
+func (s *Session) Set(key string, value any) {
+	s.data.Values[key] = value
+	s.modified = true
+}
+
+// Delete 删除会话数据
+func (s *Session) Delete(key string) {
+	delete(s.data.Values, key)
+	s.modified = true
+}
+
+// Clear 清空会话数据
+func (s *Session) Clear() {
+	s.data.Values = make(map[string]any)
+	s.modified = true
+}
+
+// Rotate 更换会话ID并保留会话数据 登录等权限变更时调用以防止会话固定攻击
+// 同时清除会话中的CSRF token 需重新调用Request.CsrfToken下发新的token
+func (s *Session) Rotate() {
+	if s.oldId == "" && !s.isNew {
+		s.oldId = s.data.Id
+	}
+	s.data.Id = newSessionId()
+	delete(s.data.Values, sessionCsrfKey)
+	s.modified = true
+}
+
+// Destroy 销毁会话 登出时调用
+func (s *Session) Destroy() {
+	s.data.Values = make(map[string]any)
+	s.destroyed = true
+}
+
+// AddFlash 添加一次性消息 在下次读取后清除
+func (s *Session) AddFlash(value any, category ...string) {
+	key := sessionFlashCategory(category)
+	flashes, _ := s.data.Values[sessionFlashKey].(map[string]any)
+	if flashes == nil {
+		flashes = make(map[string]any)
+	}
+	values, _ := flashes[key].([]any)
+	flashes[key] = append(values, value)
+	s.Set(sessionFlashKey, flashes)
+}
+
+// Flashes 读取并清除一次性消息
+func (s *Session) Flashes(category ...string) []any {
+	key := sessionFlashCategory(category)
+	flashes, _ := s.data.Values[sessionFlashKey].(map[string]any)
+	values, ok := flashes[key].([]any)
+	if !ok {
+		return nil
+	}
+	delete(flashes, key)
+	if len(flashes) == 0 {
+		s.Delete(sessionFlashKey)
+	} else {
+		s.Set(sessionFlashKey, flashes)
+	}
+	return values
+}
+
+func sessionFlashCategory(category []string) string {
+	if len(category) > 0 {
+		return category[0]
+	}
+	return ""
+}
+
+// SessionValue 获取指定类型的会话数据 类型不一致时尝试通过JSON转换
+//
+//	user, ok := ginstarter.SessionValue[LoginUser](request, "user")
+func SessionValue[T any](request *Request, key string) (T, bool) {
+	return sessionValue[T](request.Session(), key)
+}
+
+func sessionValue[T any](session *Session, key string) (T, bool) {
+	var target T
+	v, ok := session.data.Values[key]
+	if !ok {
+		return target, false
+	}
+	if t, ok := v.(T); ok {
+		return t, true
+	}
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return target, false
+	}
+	if err = json.Unmarshal(bytes, &target); err != nil {
+		return target, false
+	}
+	return target, true
+}
+
+// 在响应写入前保存会话
+type sessionWriter struct {
+	gin.ResponseWriter
+	state *sessionState
+}
+
+func (w *sessionWriter) WriteHeader(code int) {
+	w.state.commit(w.ResponseWriter)
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *sessionWriter) WriteHeaderNow() {
+	w.state.commit(w.ResponseWriter)
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *sessionWriter) Write(data []byte) (int, error) {
+	w.state.commit(w.ResponseWriter)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *sessionWriter) WriteString(s string) (int, error) {
+	w.state.commit(w.ResponseWriter)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// MemorySessionStore 内存会话存储 仅适用于单实例部署
+type MemorySessionStore struct {
+	mutex     sync.Mutex
+	sessions  map[string]*memorySession
+	lastSweep time.Time
+}
+
+type memorySession struct {
+	data     []byte
+	expireAt time.Time
+}
+
+// NewMemorySessionStore 创建内存会话存储
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[string]*memorySession), lastSweep: time.Now()}
+}
+
+func (m *MemorySessionStore) Load(_ context.Context, id string) ([]byte, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	session, ok := m.sessions[id]
+	if !ok || time.Now().After(session.expireAt) {
+		return nil, nil
+	}
+	return session.data, nil
+}
+
+func (m *MemorySessionStore) Save(_ context.Context, id string, data []byte, ttl time.Duration) error {
+	now := time.Now()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if now.Sub(m.lastSweep) > time.Minute {
+		for k, v := range m.sessions {
+			if now.After(v.expireAt) {
+				delete(m.sessions, k)
+			}
+		}
+		m.lastSweep = now
+	}
+	m.sessions[id] = &memorySession{data: data, expireAt: now.Add(ttl)}
+	return nil
+}
+
+func (m *MemorySessionStore) Delete(_ context.Context, id string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.sessions, id)
+	return nil
+}
@@ -0,0 +1,379 @@
+package ginstarter
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/acexy/golang-toolkit/logger"
+)
+
+/**
+API Key及HMAC请求签名认证
+API Key格式: 密钥标识.密钥 以密钥标识查询访问密钥后校验密钥 密钥标识不可包含.
+默认待签名字符串: method + "\n" + path + "\n" + query + "\n" + timestamp + "\n" + nonce + "\n" + hex(sha256(body))
+query为按参数名及参数值排序并重新编码的查询字符串 如 a=1&b=2&b=3
+签名: hex(hmac(secret, 待签名字符串)) 也支持base64编码
+*/
+
+// ApiKey 访问密钥
+type ApiKey struct {
+	// 密钥标识
+	Id string
+	// 密钥 API Key认证时与请求携带的密钥比较 签名认证时作为签名密钥
+	Secret []byte
+	// 密钥的sha256摘要 设置后API Key认证使用摘要比较 无需保存密钥明文 不可用于签名认证
+	SecretHash []byte
+	// 认证主体 默认使用ApiKeyPrincipal
+	Principal Principal
+}
+
+// ApiKeyPrincipal 默认的API Key认证主体
+type ApiKeyPrincipal struct {
+	KeyId string
+}
+
+func (a *ApiKeyPrincipal) Subject() string {
+	return a.KeyId
+}
+
+// ApiKeyStore 访问密钥查询接口
+type ApiKeyStore interface {
+	// Lookup 根据密钥标识获取访问密钥 不存在时返回nil
+	Lookup(ctx context.Context, keyId string) (*ApiKey, error)
+}
+
+// ApiKeyStoreFunc 函数形式的ApiKeyStore
+type ApiKeyStoreFunc func(ctx context.Context, keyId string) (*ApiKey, error)
+
+func (f ApiKeyStoreFunc) Lookup(ctx context.Context, keyId string) (*ApiKey, error) {
+	return f(ctx, keyId)
+}
+
+// NewApiKeyStore 使用固定的密钥创建ApiKeyStore key: 密钥标识 value: 密钥
+// API Key认证与签名认证应使用不同的ApiKeyStore 签名请求会明文携带密钥标识
+func NewApiKeyStore(keys map[string]string) ApiKeyStore {
+	return ApiKeyStoreFunc(func(_ context.Context, keyId string) (*ApiKey, error) {
+		secret, ok := keys[keyId]
+		if !ok {
+			return nil, nil
+		}
+		return &ApiKey{Id: keyId, Secret: []byte(secret)}, nil
+	})
+}
+
+// NonceStore 防重放nonce存储 可实现该接口使用Redis等外部存储
+type NonceStore interface {
+	// Use 记录nonce 在ttl内已使用过时返回false
+	Use(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+}
+
+// SignatureParts 参与签名的请求信息
+type SignatureParts struct {
+	KeyId  string
+	Method string
+	Path   string
+	// 规范化的查询字符串 按参数名及参数值排序后编码
+	Query     string
+	Timestamp string
+	Nonce     string
+	// 请求body的sha256 hex编码
+	BodyHash string
+}
+
+// ApiKeyConfig API Key认证配置
+type ApiKeyConfig struct {
+	// * 访问密钥查询 以API Key中的密钥标识查询 并校验密钥
+	Store ApiKeyStore
+	// 请求头名称 默认X-Api-Key
+	HeaderName string
+	// 从Query参数获取 在请求头不存在时使用
+	QueryParamName string
+}
+
+// SignatureConfig HMAC请求签名认证配置
+type SignatureConfig struct {
+	// * 访问密钥查询
+	Store ApiKeyStore
+
+	// 密钥标识请求头 默认X-Api-Key
+	KeyIdHeader string
+	// 时间戳请求头 默认X-Timestamp 支持秒及毫秒
+	TimestampHeader string
+	// nonce请求头 默认X-Nonce
+	NonceHeader string
+	// 签名请求头 默认X-Signature
+	SignatureHeader string
+
+	// 签名哈希算法 默认sha256
+	Hash func() hash.Hash
+	// 自定义待签名字符串
+	CanonicalString func(request *Request, parts *SignatureParts) string
+
+	// 允许的客户端时钟偏差 默认5分钟
+	ClockSkew time.Duration
+	// nonce存储 默认使用内存存储
+	NonceStore NonceStore
+	// 禁用nonce防重放校验
+	DisableNonce bool
+	// 请求body最大字节数 默认1MB 超出时响应413
+	MaxBodySize int64
+}
+
+// ApiKeyInterceptor API Key认证拦截器 认证失败时响应401
+// match 满足指定条件才执行
+func ApiKeyInterceptor(config *ApiKeyConfig, match ...func(request *Request) bool) PreInterceptor {
+	headerName := config.HeaderName
+	if headerName == "" {
+		headerName = "X-Api-Key"
+	}
+	return func(request *Request) (Response, bool, bool) {
+		if len(match) > 0 {
+			if !match[0](request) {
+				return nil, true, true
+			}
+		}
+		value := request.GetHeader(headerName)
+		if value == "" && config.QueryParamName != "" {
+			value, _ = request.GetQueryParam(config.QueryParamName)
+		}
+		keyId, secret, ok := strings.Cut(value, ".")
+		if !ok || keyId == "" || secret == "" {
+			return RespHttpStatusCode(http.StatusUnauthorized), false, false
+		}
+		apiKey, err := config.Store.Lookup(request.Context(), keyId)
+		if err != nil {
+			logger.Logrus().Errorln("api key lookup error:", err)
+			return RespHttpStatusCode(http.StatusServiceUnavailable), false, false
+		}
+		if apiKey == nil || !apiKeySecretMatched(apiKey, secret) {
+			logger.Logrus().Warningln("api key rejected key:", keyId)
+			return RespHttpStatusCode(http.StatusUnauthorized), false, false
+		}
+		request.SetPrincipal(apiKeyPrincipal(apiKey))
+		return nil, true, true
+	}
+}
+
+// SignatureInterceptor HMAC请求签名认证拦截器 认证失败时响应401
+// match 满足指定条件才执行
+func SignatureInterceptor(config *SignatureConfig, match ...func(request *Request) bool) PreInterceptor {
+	c := *config
+	if c.KeyIdHeader == "" {
+		c.KeyIdHeader = "X-Api-Key"
+	}
+	if c.TimestampHeader == "" {
+		c.TimestampHeader = "X-Timestamp"
+	}
+	if c.NonceHeader == "" {
+		c.NonceHeader = "X-Nonce"
+	}
+	if c.SignatureHeader == "" {
+		c.SignatureHeader = "X-Signature"
+	}
+	if c.Hash == nil {
+		c.Hash = sha256.New
+	}
+	if c.CanonicalString == nil {
+		c.CanonicalString = DefaultSignatureCanonicalString
+	}
+	if c.ClockSkew <= 0 {
+		c.ClockSkew = 5 * time.Minute
+	}
+	if c.NonceStore == nil && !c.DisableNonce {
+		c.NonceStore = NewMemoryNonceStore()
+	}
+	if c.MaxBodySize <= 0 {
+		c.MaxBodySize = 1 << 20
+	}
+	return func(request *Request) (Response, bool, bool) {
+		if len(match) > 0 {
+			if !match[0](request) {
+				return nil, true, true
+			}
+		}
+		parts := &SignatureParts{
+			KeyId:     request.GetHeader(c.KeyIdHeader),
+			Method:    request.HttpMethod(),
+			Path:      request.RequestPath(),
+			Timestamp: request.GetHeader(c.TimestampHeader),
+			Nonce:     request.GetHeader(c.NonceHeader),
+		}
+		query, err := canonicalSignatureQuery(request.ctx.Request.URL.RawQuery)
+		if err != nil {
+			return RespHttpStatusCode(http.StatusUnauthorized), false, false
+		}
+		parts.Query = query
+		signature := request.GetHeader(c.SignatureHeader)
+		if parts.KeyId == "" || parts.Timestamp == "" || signature == "" || (parts.Nonce == "" && !c.DisableNonce) {
+			return RespHttpStatusCode(http.StatusUnauthorized), false, false
+		}
+		timestamp, err := parseSignatureTimestamp(parts.Timestamp)
+		if err != nil {
+			return RespHttpStatusCode(http.StatusUnauthorized), false, false
+		}
+		if skew := time.Since(timestamp); skew > c.ClockSkew || skew < -c.ClockSkew {
+			logger.Logrus().Warningln("signature timestamp out of range key:", parts.KeyId, "skew:", skew)
+			return RespHttpStatusCode(http.StatusUnauthorized), false, false
+		}
+		apiKey, err := c.Store.Lookup(request.Context(), parts.KeyId)
+		if err != nil {
+			logger.Logrus().Errorln("api key lookup error:", err)
+			return RespHttpStatusCode(http.StatusServiceUnavailable), false, false
+		}
+		if apiKey == nil {
+			return RespHttpStatusCode(http.StatusUnauthorized), false, false
+		}
+		body, err := readSignatureBody(request, c.MaxBodySize)
+		if err != nil {
+			var maxBytesError *http.MaxBytesError
+			if errors.As(err, &maxBytesError) {
+				return RespHttpStatusCode(http.StatusRequestEntityTooLarge), false, false
+			}
+			return RespHttpStatusCode(http.StatusBadRequest), false, false
+		}
+		bodyHash := sha256.Sum256(body)
+		parts.BodyHash = hex.EncodeToString(bodyHash[:])
+
+		mac := hmac.New(c.Hash, apiKey.Secret)
+		mac.Write([]byte(c.CanonicalString(request, parts)))
+		if !hmac.Equal(mac.Sum(nil), decodeSignature(signature)) {
+			logger.Logrus().Warningln("signature mismatch key:", parts.KeyId, "path:", parts.Path)
+			return RespHttpStatusCode(http.StatusUnauthorized), false, false
+		}
+		if !c.DisableNonce {
+			ok, err := c.NonceStore.Use(request.Context(), parts.KeyId+":"+parts.Nonce, c.ClockSkew*2)
+			if err != nil {
+				logger.Logrus().Errorln("nonce store error:", err)
+				return RespHttpStatusCode(http.StatusServiceUnavailable), false, false
+			}
+			if !ok {
+				logger.Logrus().Warningln("signature nonce replayed key:", parts.KeyId, "nonce:", parts.Nonce)
+				return RespHttpStatusCode(http.StatusUnauthorized), false, false
+			}
+		}
+		request.SetPrincipal(apiKeyPrincipal(apiKey))
+		return nil, true, true
+	}
+}
+
+// 以固定时间比较密钥 未配置密钥的访问密钥始终认证失败
+func apiKeySecretMatched(apiKey *ApiKey, secret string) bool {
+	if len(apiKey.SecretHash) > 0 {
+		hash := sha256.Sum256([]byte(secret))
+		return subtle.ConstantTimeCompare(hash[:], apiKey.SecretHash) == 1
+	}
+	if len(apiKey.Secret) == 0 {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(secret), apiKey.Secret) == 1
+}
+
+// DefaultSignatureCanonicalString 默认待签名字符串
+func DefaultSignatureCanonicalString(_ *Request, parts *SignatureParts) string {
+	return strings.Join([]string{parts.Method, parts.Path, parts.Query, parts.Timestamp, parts.Nonce, parts.BodyHash}, "\n")
+}
+
+// 规范化查询字符串 参数名及参数值均排序 避免篡改或重排查询参数
+func canonicalSignatureQuery(rawQuery string) (string, error) {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", err
+	}
+	for _, v := range values {
+		slices.Sort(v)
+	}
+	return values.Encode(), nil
+}
+
+// 限制大小读取body 校验签名前不可无限缓存未认证请求的body 读取后重置body供后续绑定
+func readSignatureBody(request *Request, maxBodySize int64) ([]byte, error) {
+	httpRequest := request.ctx.Request
+	if httpRequest.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(request.ctx.Writer, httpRequest.Body, maxBodySize))
+	if err != nil {
+		return nil, err
+	}
+	httpRequest.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func apiKeyPrincipal(apiKey *ApiKey) Principal {
+	if apiKey.Principal != nil {
+		return apiKey.Principal
+	}
+	return &ApiKeyPrincipal{KeyId: apiKey.Id}
+}
+
+// 解析秒或毫秒时间戳
+func parseSignatureTimestamp(value string) (time.Time, error) {
+	v, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if v > 1e12 {
+		return time.UnixMilli(v), nil
+	}
+	return time.Unix(v, 0), nil
+}
+
+// 解析hex或base64编码的签名
+func decodeSignature(signature string) []byte {
+	if v, err := hex.DecodeString(signature); err == nil {
+		return v
+	}
+	if v, err := base64.StdEncoding.DecodeString(signature); err == nil {
+		return v
+	}
+	if v, err := base64.RawURLEncoding.DecodeString(signature); err == nil {
+		return v
+	}
+	return nil
+}
+
+// MemoryNonceStore 内存nonce存储 仅适用于单实例部署
+type MemoryNonceStore struct {
+	mutex     sync.Mutex
+	nonces    map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewMemoryNonceStore 创建内存nonce存储
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{nonces: make(map[string]time.Time), lastSweep: time.Now()}
+}
+
+func (m *MemoryNonceStore) Use(_ context.Context, nonce string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if now.Sub(m.lastSweep) > ttl {
+		for k, expireAt := range m.nonces {
+			if now.After(expireAt) {
+				delete(m.nonces, k)
+			}
+		}
+		m.lastSweep = now
+	}
+	if expireAt, ok := m.nonces[nonce]; ok && now.Before(expireAt) {
+		return false, nil
+	}
+	m.nonces[nonce] = now.Add(ttl)
+	return true, nil
+}
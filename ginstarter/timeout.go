@@ -0,0 +1,241 @@
+package ginstarter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/acexy/golang-toolkit/logger"
+	"github.com/gin-gonic/gin"
+)
+
+/**
+请求超时控制
+超时时间优先级: RouterWrapper.WithTimeout > RouterInfo.Timeout > GinConfig.RequestTimeout
+设置为负数表示禁用超时控制
+*/
+
+// 超时触发的取消原因 客户端断开或服务停止导致的取消不响应
+var errHandlerTimeout = context.DeadlineExceeded
+
+type handlerResult struct {
+	response   Response
+	err        error
+	panicError any
+	stack      string
+}
+
+// 计算生效的超时时间 0 表示继承上一级配置
+func resolveTimeout(timeouts ...time.Duration) time.Duration {
+	for _, timeout := range timeouts {
+		if timeout != 0 {
+			return timeout
+		}
+	}
+	return 0
+}
+
+// 在超时控制下执行handler
+// handler在独立的协程中使用请求上下文的副本执行 其直接写入的响应数据将被缓存 按时完成后回放至原始响应 并同步上下文的修改
+// 超时或客户端断开后Request.Context()将被取消 handler后续的写入及返回结果将被丢弃 abandoned为取消原因
+// 被放弃的handler协程结束前 其占用的并发额度不会释放
+func invokeWithTimeout(request *Request, handler HandlerWrapper, timeout time.Duration) (response Response, err error, abandoned error) {
+	ctx, cancel := context.WithTimeout(request.Context(), timeout)
+	defer cancel()
+
+	writer := newTimeoutWriter()
+	copied := request.ctx.Copy()
+	copied.Writer = writer
+	copied.Request = copied.Request.WithContext(ctx)
+
+	done := make(chan handlerResult, 1)
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		var result handlerResult
+		defer func() {
+			if panicError := recover(); panicError != nil {
+				if writer.isDiscarded() {
+					logger.Logrus().Errorln("panic after request abandoned path:", copied.Request.URL.Path, "error:", panicError)
+					return
+				}
+				result.panicError = panicError
+				result.stack = string(debug.Stack())
+			}
+			done <- result
+		}()
+		result.response, result.err = handler(&Request{ctx: copied})
+	}()
+
+	var result handlerResult
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		select {
+		case result = <-done:
+		default:
+			writer.discard()
+			request.ctx.Set(ginCtxKeyAbandonedHandler, (<-chan struct{})(finished))
+			abandoned = context.Cause(ctx)
+			if errors.Is(abandoned, errHandlerTimeout) {
+				logger.Logrus().Warningln("request timeout path:", request.RequestPath(), "timeout:", timeout)
+			} else {
+				logger.Logrus().Debugln("request canceled path:", request.RequestPath(), "cause:", abandoned)
+			}
+			return nil, nil, abandoned
+		}
+	}
+	if result.panicError != nil {
+		// 携带handler协程的调用栈 避免所有超时路由的panic具有相同的调用栈
+		panic(&recoveredPanic{value: result.panicError, stack: result.stack})
+	}
+	for k, v := range copied.Keys {
+		request.ctx.Set(k, v)
+	}
+	// 保留handler通过SetContext SetPrincipal等写入的上下文数据
+	request.ctx.Request = request.ctx.Request.WithContext(&handlerValuesContext{
+		Context: request.ctx.Request.Context(),
+		values:  copied.Request.Context(),
+	})
+	writer.replay(request.ctx)
+	return result.response, result.err, nil
+}
+
+// 取消信号来自原始请求上下文 数据来自handler协程的上下文
+type handlerValuesContext struct {
+	context.Context
+	values context.Context
+}
+
+func (c *handlerValuesContext) Value(key any) any {
+	return c.values.Value(key)
+}
+
+// 被放弃的handler协程结束后执行release 未被放弃时立即执行
+func releaseAfterHandler(ctx *gin.Context, release func()) {
+	if v, ok := ctx.Get(ginCtxKeyAbandonedHandler); ok {
+		finished := v.(<-chan struct{})
+		go func() {
+			<-finished
+			release()
+		}()
+		return
+	}
+	release()
+}
+
+// 超时控制下handler使用的响应写入器 缓存所有写入 超时后丢弃
+type timeoutWriter struct {
+	mutex      sync.Mutex
+	header     http.Header
+	body       bytes.Buffer
+	statusCode int
+	written    bool
+	discarded  bool
+}
+
+func newTimeoutWriter() *timeoutWriter {
+	return &timeoutWriter{
+		header:     make(http.Header),
+		statusCode: http.StatusOK,
+	}
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.discarded {
+		return 0, http.ErrHandlerTimeout
+	}
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.discarded || w.written {
+		return
+	}
+	w.statusCode = code
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.written = true
+}
+
+func (w *timeoutWriter) Status() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.statusCode
+}
+
+func (w *timeoutWriter) Size() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.body.Len()
+}
+
+func (w *timeoutWriter) Written() bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.written
+}
+
+func (w *timeoutWriter) Flush() {
+}
+
+func (w *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errors.New("hijack not supported with request timeout")
+}
+
+func (w *timeoutWriter) CloseNotify() <-chan bool {
+	return make(chan bool)
+}
+
+func (w *timeoutWriter) Pusher() http.Pusher {
+	return nil
+}
+
+func (w *timeoutWriter) discard() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.discarded = true
+}
+
+func (w *timeoutWriter) isDiscarded() bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.discarded
+}
+
+// 将缓存的响应数据回放至原始响应
+func (w *timeoutWriter) replay(ctx *gin.Context) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	for name, values := range w.header {
+		ctx.Writer.Header()[name] = values
+	}
+	if w.written || w.statusCode != http.StatusOK {
+		ctx.Status(w.statusCode)
+	}
+	if w.body.Len() > 0 {
+		_, _ = ctx.Writer.Write(w.body.Bytes())
+	}
+}
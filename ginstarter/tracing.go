@@ -0,0 +1,493 @@
+package ginstarter
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	mathrand "math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/acexy/golang-toolkit/logger"
+	"github.com/gin-gonic/gin"
+)
+
+/**
+链路追踪
+遵循W3C Trace Context 解析请求头traceparent tracestate baggage 为每个请求创建服务端Span
+Span以路由模板命名 记录状态码及panic 结束后交由SpanExporter异步导出 可在SpanExporter中桥接OpenTelemetry等实现
+TraceId保存在请求上下文中 日志关联可通过Request.TraceId()或TraceIdFromContext获取
+*/
+
+// SpanStatusCode Span状态
+type SpanStatusCode int
+
+const (
+	SpanStatusUnset SpanStatusCode = iota
+	SpanStatusOk
+	SpanStatusError
+)
+
+// SpanKind Span类型
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = iota
+	SpanKindServer
+	SpanKindClient
+)
+
+// SpanExporter 导出已结束的Span 在独立的导出协程中依次调用 队列已满时丢弃
+type SpanExporter interface {
+	ExportSpan(span *Span)
+}
+
+// SpanExporterFunc 函数形式的SpanExporter
+type SpanExporterFunc func(span *Span)
+
+func (f SpanExporterFunc) ExportSpan(span *Span) {
+	f(span)
+}
+
+// TracingConfig 链路追踪配置
+type TracingConfig struct {
+	// 服务名称 作为Span属性service.name
+	ServiceName string
+	// 无上游traceparent时的采样率 (0,1) 之间时按比例采样 其他值全部采样 存在上游traceparent时遵循上游的采样标记
+	SampleRate float64
+	// Span导出器 仅导出被采样的Span
+	Exporter SpanExporter
+	// Span导出队列长度 默认1000
+	ExportQueueSize int
+	// 禁用Trace-Id响应头
+	DisableTraceIdHeader bool
+	// 不追踪的请求路径 以*结尾时按前缀匹配
+	ExcludePaths []string
+}
+
+// SpanEvent Span事件
+type SpanEvent struct {
+	Name       string
+	Time       time.Time
+	Attributes map[string]any
+}
+
+// Span 追踪单元 所有方法均可在nil上安全调用
+type Span struct {
+	mutex sync.Mutex
+
+	TraceId      string
+	SpanId       string
+	ParentSpanId string
+	TraceState   string
+	Sampled      bool
+	Remote       bool
+	Kind         SpanKind
+	Name         string
+	StartTime    time.Time
+	EndTime      time.Time
+
+	Status        SpanStatusCode
+	StatusMessage string
+	Attributes    map[string]any
+	Events        []SpanEvent
+	Baggage       map[string]string
+
+	ended bool
+}
+
+var tracing *TracingConfig
+var contextKeySpan = NewContextKey[*Span]("span")
+
+// Span导出队列
+type spanExporter struct {
+	exporter SpanExporter
+	queue    chan *Span
+	done     chan struct{}
+
+	mutex  sync.Mutex
+	closed bool
+}
+
+var spanExporting atomic.Pointer[spanExporter]
+
+func initTracing(config *TracingConfig) {
+	tracing = config
+	if previous := spanExporting.Swap(nil); previous != nil {
+		previous.close()
+	}
+	if config == nil || config.Exporter == nil {
+		return
+	}
+	queueSize := config.ExportQueueSize
+	if queueSize <= 0 {
+		queueSize = 1000
+	}
+	exporter := &spanExporter{
+		exporter: config.Exporter,
+		queue:    make(chan *Span, queueSize),
+		done:     make(chan struct{}),
+	}
+	go exporter.run()
+	spanExporting.Store(exporter)
+}
+
+// 停止导出 等待队列中的Span导出完成
+func stopTracing(ctx context.Context) {
+	exporter := spanExporting.Swap(nil)
+	if exporter == nil {
+		return
+	}
+	exporter.close()
+	select {
+	case <-exporter.done:
+	case <-ctx.Done():
+	}
+}
+
+func (e *spanExporter) run() {
+	defer close(e.done)
+	for span := range e.queue {
+		e.export(span)
+	}
+}
+
+func (e *spanExporter) export(span *Span) {
+	defer func() {
+		if err := recover(); err != nil {
+			logger.Logrus().Errorln("span exporter failed:", err)
+		}
+	}()
+	e.exporter.ExportSpan(span)
+}
+
+func (e *spanExporter) enqueue(span *Span) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.closed {
+		return
+	}
+	select {
+	case e.queue <- span:
+	default:
+		logger.Logrus().Warningln("span export queue is full, span dropped name:", span.Name)
+	}
+}
+
+func (e *spanExporter) close() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if !e.closed {
+		e.closed = true
+		close(e.queue)
+	}
+}
+
+// SetName 设置Span名称
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.Name = name
+}
+
+// SetAttribute 设置Span属性
+func (s *Span) SetAttribute(key string, value any) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.Attributes == nil {
+		s.Attributes = make(map[string]any)
+	}
+	s.Attributes[key] = value
+}
+
+// AddEvent 添加Span事件
+func (s *Span) AddEvent(name string, attributes map[string]any) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.Events = append(s.Events, SpanEvent{Name: name, Time: time.Now(), Attributes: attributes})
+}
+
+// SetStatus 设置Span状态
+func (s *Span) SetStatus(code SpanStatusCode, message string) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.Status = code
+	s.StatusMessage = message
+}
+
+// RecordError 记录异常事件并将Span标记为错误
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.AddEvent("exception", map[string]any{
+		"exception.type":    fmt.Sprintf("%T", err),
+		"exception.message": err.Error(),
+	})
+	s.SetStatus(SpanStatusError, err.Error())
+}
+
+// BaggageValue 获取上游传递的baggage
+func (s *Span) BaggageValue(key string) string {
+	if s == nil {
+		return ""
+	}
+	return s.Baggage[key]
+}
+
+// Traceparent 当前Span的W3C traceparent 用于向下游传递
+func (s *Span) Traceparent() string {
+	if s == nil {
+		return ""
+	}
+	flags := "00"
+	if s.Sampled {
+		flags = "01"
+	}
+	return "00-" + s.TraceId + "-" + s.SpanId + "-" + flags
+}
+
+// End 结束Span 被采样时加入导出队列 重复调用无效
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	if s.ended {
+		s.mutex.Unlock()
+		return
+	}
+	s.ended = true
+	s.EndTime = time.Now()
+	s.mutex.Unlock()
+	if s.Sampled {
+		if exporter := spanExporting.Load(); exporter != nil {
+			exporter.enqueue(s)
+		}
+	}
+}
+
+// Span 获取当前请求的服务端Span 未启用链路追踪时返回nil
+func (r *Request) Span() *Span {
+	span, _ := contextKeySpan.Get(r)
+	return span
+}
+
+// SpanFromContext 从派生自请求上下文的context.Context中获取当前Span
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := contextKeySpan.Value(ctx)
+	return span
+}
+
+// StartSpan 创建当前Span的子Span 使用完成后需调用End 未启用链路追踪时返回nil
+//
+//	ctx, span := ginstarter.StartSpan(request.Context(), "query-user")
+//	defer span.End()
+func StartSpan(ctx context.Context, name string) (context.Context, *Span) {
+	if tracing == nil {
+		return ctx, nil
+	}
+	parent := SpanFromContext(ctx)
+	span := &Span{
+		SpanId:    newSpanId(),
+		Kind:      SpanKindInternal,
+		Name:      name,
+		StartTime: time.Now(),
+	}
+	if parent != nil {
+		span.TraceId = parent.TraceId
+		span.ParentSpanId = parent.SpanId
+		span.TraceState = parent.TraceState
+		span.Sampled = parent.Sampled
+		span.Baggage = parent.Baggage
+	} else {
+		span.TraceId = newTraceId()
+		span.Sampled = tracingSampled()
+	}
+	return context.WithValue(ctx, contextKeySpan, span), span
+}
+
+// InjectTraceHeaders 将当前Span的traceparent tracestate baggage及请求ID写入下游请求头
+func InjectTraceHeaders(ctx context.Context, header http.Header) {
+	if requestId := RequestIdFromContext(ctx); requestId != "" && requestIdConfig != nil {
+		header.Set(requestIdConfig.HeaderName, requestId)
+	}
+	span := SpanFromContext(ctx)
+	if span == nil {
+		return
+	}
+	header.Set("traceparent", span.Traceparent())
+	if span.TraceState != "" {
+		header.Set("tracestate", span.TraceState)
+	}
+	if len(span.Baggage) > 0 {
+		members := make([]string, 0, len(span.Baggage))
+		for k, v := range span.Baggage {
+			members = append(members, k+"="+url.PathEscape(v))
+		}
+		header.Set("baggage", strings.Join(members, ","))
+	}
+}
+
+// 链路追踪中间件 在所有中间件之前执行
+func tracingHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		config := tracing
+		if config == nil || accessLogExcluded(config.ExcludePaths, ctx.Request.URL.Path) {
+			ctx.Next()
+			return
+		}
+		span := &Span{
+			SpanId:    newSpanId(),
+			Kind:      SpanKindServer,
+			StartTime: time.Now(),
+		}
+		if traceId, parentSpanId, sampled, ok := parseTraceparent(ctx.GetHeader("traceparent")); ok {
+			span.TraceId = traceId
+			span.ParentSpanId = parentSpanId
+			span.Sampled = sampled
+			span.Remote = true
+			span.TraceState = ctx.GetHeader("tracestate")
+		} else {
+			span.TraceId = newTraceId()
+			span.Sampled = tracingSampled()
+		}
+		span.Baggage = parseBaggage(ctx.GetHeader("baggage"))
+
+		requestContext := context.WithValue(ctx.Request.Context(), contextKeySpan, span)
+		requestContext = context.WithValue(requestContext, contextKeyTraceId, span.TraceId)
+		ctx.Request = ctx.Request.WithContext(requestContext)
+		if !config.DisableTraceIdHeader {
+			ctx.Header(traceIdHeaderName(), span.TraceId)
+		}
+		defer span.End()
+
+		writer := ctx.Writer
+		ctx.Next()
+
+		status := writer.Status()
+		if resolved, ok := ctx.Get(ginCtxKeyResolvedStatus); ok {
+			status = resolved.(int)
+		}
+		route := ctx.FullPath()
+		if route != "" {
+			span.SetName(ctx.Request.Method + " " + route)
+			span.SetAttribute("http.route", route)
+		} else {
+			span.SetName(ctx.Request.Method)
+		}
+		span.SetAttribute("http.request.method", ctx.Request.Method)
+		span.SetAttribute("url.path", ctx.Request.URL.Path)
+		span.SetAttribute("http.response.status_code", status)
+		span.SetAttribute("client.address", trustedClientIP(ctx))
+		span.SetAttribute("user_agent.original", ctx.Request.UserAgent())
+		if config.ServiceName != "" {
+			span.SetAttribute("service.name", config.ServiceName)
+		}
+		if panicError, ok := ctx.Get(ginCtxKeyPanic); ok {
+			span.AddEvent("exception", map[string]any{
+				"exception.type":    fmt.Sprintf("%T", panicError),
+				"exception.message": fmt.Sprint(panicError),
+			})
+		}
+		// 服务端Span仅5xx视为错误
+		if status >= http.StatusInternalServerError {
+			span.mutex.Lock()
+			if span.Status == SpanStatusUnset {
+				span.Status = SpanStatusError
+				span.StatusMessage = http.StatusText(status)
+			}
+			span.mutex.Unlock()
+		}
+	}
+}
+
+// 解析traceparent 格式 version-traceId-parentId-flags
+func parseTraceparent(value string) (traceId, parentSpanId string, sampled, ok bool) {
+	value = strings.TrimSpace(value)
+	if len(value) < 55 {
+		return
+	}
+	version := value[:2]
+	if !isLowerHex(version) || version == "ff" || (version == "00" && len(value) != 55) || (len(value) > 55 && value[55] != '-') {
+		return
+	}
+	if value[2] != '-' || value[35] != '-' || value[52] != '-' {
+		return
+	}
+	traceId = value[3:35]
+	parentSpanId = value[36:52]
+	flags := value[53:55]
+	if !isLowerHex(traceId) || !isLowerHex(parentSpanId) || !isLowerHex(flags) ||
+		traceId == strings.Repeat("0", 32) || parentSpanId == strings.Repeat("0", 16) {
+		return "", "", false, false
+	}
+	flagBits, _ := hex.DecodeString(flags)
+	return traceId, parentSpanId, flagBits[0]&0x01 == 1, true
+}
+
+// 解析baggage 格式 key1=value1;property,key2=value2
+func parseBaggage(value string) map[string]string {
+	if value == "" {
+		return nil
+	}
+	baggage := make(map[string]string)
+	for _, member := range strings.Split(value, ",") {
+		member, _, _ = strings.Cut(member, ";")
+		key, val, found := strings.Cut(member, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			continue
+		}
+		if decoded, err := url.PathUnescape(strings.TrimSpace(val)); err == nil {
+			baggage[key] = decoded
+		}
+	}
+	return baggage
+}
+
+func isLowerHex(value string) bool {
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func tracingSampled() bool {
+	if tracing == nil || tracing.SampleRate <= 0 || tracing.SampleRate >= 1 {
+		return true
+	}
+	return mathrand.Float64() < tracing.SampleRate
+}
+
+func newTraceId() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+func newSpanId() string {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
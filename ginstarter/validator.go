@@ -1,11 +1,15 @@
 package ginstarter
 
 import (
+	"reflect"
 	"regexp"
+	"strings"
 
+	"github.com/acexy/golang-toolkit/logger"
 	"github.com/acexy/golang-toolkit/util/coll"
 	"github.com/acexy/golang-toolkit/util/str"
 	"github.com/gin-gonic/gin/binding"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -28,26 +32,122 @@ var typeDesc = []string{
 	"numeric",
 	"base64",
 	"datetime",
-	"domain",
+}
+
+// 系统内置的拓展验证tag
+var builtinValidatorTags = []*ValidatorTag{
+	{
+		Tag:      "domain",
+		Func:     domainValidator,
+		TypeDesc: true,
+		Messages: map[string]string{
+			LocaleEn: "{0} must be a valid domain",
+			LocaleZh: "{0}必须是一个有效的域名",
+		},
+	},
+}
+
+// 已注册的拓展验证tag错误信息 tag -> message
+var validatorTagMessages map[string]*validatorTagMessage
+
+type validatorTagMessage struct {
+	typeDesc bool
+	message  string
+	messages map[string]string
+}
+
+// ValidatorConfig 验证器拓展配置
+type ValidatorConfig struct {
+	// 自定义验证tag
+	Tags []*ValidatorTag
+	// 验证tag别名
+	Aliases []*ValidatorAlias
+	// 结构体级别验证
+	StructLevels []*ValidatorStructLevel
+	// 自定义类型取值
+	CustomTypes []*ValidatorCustomType
+	// 直接操作验证器实例 在以上配置注册完成后执行
+	RegisterFunc func(v *validator.Validate)
+
+	// password验证tag使用的密码强度策略 不设置则使用默认策略
+	PasswordPolicy *PasswordPolicy
+}
+
+// ValidatorTag 自定义验证tag
+type ValidatorTag struct {
+	// 标签名
+	Tag string
+	// 验证函数 为空时仅注册错误信息 用于结构体级别验证中通过ReportError上报的tag
+	Func validator.Func
+	// 字段为空值时也执行验证
+	CallValidationEvenIfNull bool
+
+	// 类型描述标签 未设置错误信息时展示为 field mismatch type tag
+	TypeDesc bool
+	// 错误信息模板 未启用国际化或Messages中无对应语言时使用 {0}为字段名 {1}为标签匹配值
+	Message string
+	// 国际化错误信息模板 locale -> message {0}为字段名 {1}为标签匹配值
+	Messages map[string]string
+}
+
+// ValidatorAlias 验证tag别名 例如 Alias: "iscolor" Tags: "hexcolor|rgb|rgba"
+type ValidatorAlias struct {
+	Alias string
+	Tags  string
+
+	// 错误信息模板 同ValidatorTag
+	Message  string
+	Messages map[string]string
+}
+
+// ValidatorStructLevel 结构体级别验证 作用于Types中指定的结构体类型
+type ValidatorStructLevel struct {
+	Func  validator.StructLevelFunc
+	Types []any
+}
+
+// ValidatorCustomType 自定义类型取值 用于sql.NullString等类型参与验证
+type ValidatorCustomType struct {
+	Func  validator.CustomTypeFunc
+	Types []any
 }
 
 // friendlyValidatorMessage 处理验证框架错误，友好展示错误信息
-func friendlyValidatorMessage(errors validator.ValidationErrors) string {
+// trans 不为空时优先使用翻译信息
+func friendlyValidatorMessage(errors validator.ValidationErrors, trans ut.Translator) string {
 	builder := str.NewBuilder()
 	for i, vErr := range errors {
-		// 字段名
-		builder.WriteString(str.LowFirstChar(vErr.Field()))
+		if trans != nil {
+			if message := vErr.Translate(trans); message != vErr.Error() {
+				builder.WriteString(message)
+				if i != len(errors)-1 {
+					builder.WriteString("; ")
+				}
+				continue
+			}
+		}
+		// 字段名 未启用国际化时使用结构体字段名 不受字段名解析函数影响
+		field := str.LowFirstChar(vErr.StructField())
+		if trans != nil {
+			field = vErr.Field()
+		}
 		// 验证标签
 		tag := vErr.Tag()
-		if coll.SliceContains(typeDesc, tag) {
-			builder.WriteString(" mismatch type ").WriteString(tag)
-		} else {
-			builder.WriteString(" ").WriteString(tag)
-		}
 		// 标签匹配值
 		param := vErr.Param()
-		if param != "" {
-			builder.WriteString(" ").WriteString(param)
+		tagMessage := validatorTagMessages[tag]
+		if tagMessage != nil && tagMessage.message != "" {
+			builder.WriteString(formatValidatorMessage(tagMessage.message, field, param))
+		} else {
+			builder.WriteString(field)
+			if coll.SliceContains(typeDesc, tag) || (tagMessage != nil && tagMessage.typeDesc) {
+				builder.WriteString(" mismatch type ").WriteString(tag)
+			} else {
+				builder.WriteString(" ").WriteString(tag)
+			}
+			if param != "" {
+				builder.WriteString(" ").WriteString(param)
+			}
 		}
 		if i != len(errors)-1 {
 			builder.WriteString("; ")
@@ -56,9 +156,95 @@ func friendlyValidatorMessage(errors validator.ValidationErrors) string {
 	return builder.ToString()
 }
 
-func registerValidators() {
-	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
-		_ = v.RegisterValidation("domain", domainValidator)
+// 验证错误中的字段名 依次使用json form uri tag 忽略声明为-的tag 均未声明时使用结构体字段名
+func validatorFieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func formatValidatorMessage(message, field, param string) string {
+	return strings.NewReplacer("{0}", field, "{1}", param).Replace(message)
+}
+
+func registerValidators(config *ValidatorConfig) {
+	validatorTagMessages = make(map[string]*validatorTagMessage)
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	tags := append(append([]*ValidatorTag{}, builtinValidatorTags...), businessValidatorTags...)
+	passwordPolicy = defaultPasswordPolicy()
+	if config != nil {
+		tags = append(tags, config.Tags...)
+		if config.PasswordPolicy != nil {
+			passwordPolicy = config.PasswordPolicy
+		}
+	}
+	for _, tag := range tags {
+		if tag == nil {
+			continue
+		}
+		if tag.Func != nil {
+			if err := v.RegisterValidation(tag.Tag, tag.Func, tag.CallValidationEvenIfNull); err != nil {
+				logger.Logrus().Warningln("register validation tag error tag:", tag.Tag, err)
+				continue
+			}
+		}
+		validatorTagMessages[tag.Tag] = &validatorTagMessage{
+			typeDesc: tag.TypeDesc,
+			message:  tag.Message,
+			messages: tag.Messages,
+		}
+	}
+	if config == nil {
+		return
+	}
+	for _, alias := range config.Aliases {
+		if alias == nil {
+			continue
+		}
+		v.RegisterAlias(alias.Alias, alias.Tags)
+		validatorTagMessages[alias.Alias] = &validatorTagMessage{
+			message:  alias.Message,
+			messages: alias.Messages,
+		}
+	}
+	for _, structLevel := range config.StructLevels {
+		if structLevel != nil {
+			v.RegisterStructValidation(structLevel.Func, structLevel.Types...)
+		}
+	}
+	for _, customType := range config.CustomTypes {
+		if customType != nil {
+			v.RegisterCustomTypeFunc(customType.Func, customType.Types...)
+		}
+	}
+	if config.RegisterFunc != nil {
+		config.RegisterFunc(v)
+	}
+}
+
+// 注册拓展验证tag的翻译信息
+func registerValidatorTranslations(v *validator.Validate, locale string, trans ut.Translator) {
+	for tag, tagMessage := range validatorTagMessages {
+		message, ok := tagMessage.messages[locale]
+		if !ok {
+			message = tagMessage.message
+		}
+		if message == "" {
+			continue
+		}
+		_ = v.RegisterTranslation(tag, trans, func(ut ut.Translator) error {
+			return ut.Add(tag, message, true)
+		}, func(ut ut.Translator, fe validator.FieldError) string {
+			t, _ := ut.T(fe.Tag(), fe.Field(), fe.Param())
+			return t
+		})
 	}
 }
 
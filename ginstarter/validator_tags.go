@@ -0,0 +1,312 @@
+package ginstarter
+
+import (
+	"net"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+/**
+拓展验证tag 常用业务格式
+mobile: 中国大陆手机号码
+idcard: 18位居民身份证号码 (校验出生日期与校验码)
+uscc: 统一社会信用代码 (校验校验码)
+bankcard: 银行卡号 (Luhn校验)
+password: 密码强度 见PasswordPolicy
+cidrs: 以逗号分隔的CIDR列表
+filename: 安全的文件名
+semver timezone iso4217: 使用验证框架内置的验证 仅补充错误信息
+*/
+
+var mobileRegex = regexp.MustCompile(`^(?:\+?86)?1[3-9]\d{9}$`)
+
+var idCardWeights = []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+
+const idCardCheckCodes = "10X98765432"
+
+const usccCharset = "0123456789ABCDEFGHJKLMNPQRTUWXY"
+
+var usccWeights = []int{1, 3, 9, 27, 19, 26, 16, 17, 20, 29, 25, 13, 8, 24, 10, 30, 28}
+
+var reservedFilenames = []string{
+	"CON", "PRN", "AUX", "NUL",
+	"COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
+	"LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9",
+}
+
+// PasswordPolicy 密码强度策略 作用于password验证tag
+type PasswordPolicy struct {
+	// 最小长度 默认 8
+	MinLength int
+	// 最大长度 默认 64
+	MaxLength int
+	// 至少包含的字符种类数 (小写字母 大写字母 数字 特殊字符) 默认 3
+	MinCharClasses int
+	// 允许包含空白字符
+	AllowWhitespace bool
+}
+
+var passwordPolicy = defaultPasswordPolicy()
+
+func defaultPasswordPolicy() *PasswordPolicy {
+	return &PasswordPolicy{
+		MinLength:      8,
+		MaxLength:      64,
+		MinCharClasses: 3,
+	}
+}
+
+var businessValidatorTags = []*ValidatorTag{
+	{
+		Tag:      "mobile",
+		Func:     mobileValidator,
+		TypeDesc: true,
+		Messages: map[string]string{
+			LocaleEn: "{0} must be a valid mobile number",
+			LocaleZh: "{0}必须是一个有效的手机号码",
+		},
+	},
+	{
+		Tag:      "idcard",
+		Func:     idCardValidator,
+		TypeDesc: true,
+		Messages: map[string]string{
+			LocaleEn: "{0} must be a valid resident ID card number",
+			LocaleZh: "{0}必须是一个有效的身份证号码",
+		},
+	},
+	{
+		Tag:      "uscc",
+		Func:     usccValidator,
+		TypeDesc: true,
+		Messages: map[string]string{
+			LocaleEn: "{0} must be a valid unified social credit code",
+			LocaleZh: "{0}必须是一个有效的统一社会信用代码",
+		},
+	},
+	{
+		Tag:      "bankcard",
+		Func:     bankCardValidator,
+		TypeDesc: true,
+		Messages: map[string]string{
+			LocaleEn: "{0} must be a valid bank card number",
+			LocaleZh: "{0}必须是一个有效的银行卡号",
+		},
+	},
+	{
+		Tag:  "password",
+		Func: passwordValidator,
+		Messages: map[string]string{
+			LocaleEn: "{0} does not meet the password strength policy",
+			LocaleZh: "{0}不满足密码强度要求",
+		},
+	},
+	{
+		Tag:      "cidrs",
+		Func:     cidrsValidator,
+		TypeDesc: true,
+		Messages: map[string]string{
+			LocaleEn: "{0} must be a valid CIDR list",
+			LocaleZh: "{0}必须是一个有效的CIDR列表",
+		},
+	},
+	{
+		Tag:      "filename",
+		Func:     filenameValidator,
+		TypeDesc: true,
+		Messages: map[string]string{
+			LocaleEn: "{0} must be a safe file name",
+			LocaleZh: "{0}必须是一个安全的文件名",
+		},
+	},
+	{
+		Tag:      "semver",
+		TypeDesc: true,
+		Messages: map[string]string{
+			LocaleEn: "{0} must be a valid semantic version",
+			LocaleZh: "{0}必须是一个有效的语义化版本号",
+		},
+	},
+	{
+		Tag:      "timezone",
+		TypeDesc: true,
+		Messages: map[string]string{
+			LocaleEn: "{0} must be a valid timezone name",
+			LocaleZh: "{0}必须是一个有效的时区名称",
+		},
+	},
+	{
+		Tag:      "iso4217",
+		TypeDesc: true,
+		Messages: map[string]string{
+			LocaleEn: "{0} must be a valid ISO 4217 currency code",
+			LocaleZh: "{0}必须是一个有效的ISO 4217货币代码",
+		},
+	},
+}
+
+// 手机号码验证器
+func mobileValidator(fl validator.FieldLevel) bool {
+	return mobileRegex.MatchString(fl.Field().String())
+}
+
+// 身份证号码验证器
+func idCardValidator(fl validator.FieldLevel) bool {
+	return isIdCard(fl.Field().String())
+}
+
+func isIdCard(value string) bool {
+	if len(value) != 18 {
+		return false
+	}
+	value = strings.ToUpper(value)
+	sum := 0
+	for i := 0; i < 17; i++ {
+		c := value[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		sum += int(c-'0') * idCardWeights[i]
+	}
+	if _, err := time.Parse("20060102", value[6:14]); err != nil {
+		return false
+	}
+	return value[17] == idCardCheckCodes[sum%11]
+}
+
+// 统一社会信用代码验证器
+func usccValidator(fl validator.FieldLevel) bool {
+	return isUscc(fl.Field().String())
+}
+
+func isUscc(value string) bool {
+	if len(value) != 18 {
+		return false
+	}
+	value = strings.ToUpper(value)
+	sum := 0
+	for i := 0; i < 17; i++ {
+		index := strings.IndexByte(usccCharset, value[i])
+		if index < 0 {
+			return false
+		}
+		sum += index * usccWeights[i]
+	}
+	check := 31 - sum%31
+	if check == 31 {
+		check = 0
+	}
+	return value[17] == usccCharset[check]
+}
+
+// 银行卡号验证器
+func bankCardValidator(fl validator.FieldLevel) bool {
+	return isBankCard(fl.Field().String())
+}
+
+func isBankCard(value string) bool {
+	if len(value) < 12 || len(value) > 19 {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(value) - 1; i >= 0; i-- {
+		c := value[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// 密码强度验证器
+func passwordValidator(fl validator.FieldLevel) bool {
+	return isStrongPassword(fl.Field().String(), passwordPolicy)
+}
+
+func isStrongPassword(value string, policy *PasswordPolicy) bool {
+	length := len([]rune(value))
+	if length < policy.MinLength || (policy.MaxLength > 0 && length > policy.MaxLength) {
+		return false
+	}
+	var lower, upper, digit, special bool
+	for _, r := range value {
+		switch {
+		case unicode.IsSpace(r):
+			if !policy.AllowWhitespace {
+				return false
+			}
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			special = true
+		}
+	}
+	classes := 0
+	for _, v := range []bool{lower, upper, digit, special} {
+		if v {
+			classes++
+		}
+	}
+	return classes >= policy.MinCharClasses
+}
+
+// CIDR列表验证器
+func cidrsValidator(fl validator.FieldLevel) bool {
+	value := strings.TrimSpace(fl.Field().String())
+	if value == "" {
+		return false
+	}
+	for _, item := range strings.Split(value, ",") {
+		if _, _, err := net.ParseCIDR(strings.TrimSpace(item)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// 安全文件名验证器 拒绝路径分隔符、控制字符、系统保留名称等
+func filenameValidator(fl validator.FieldLevel) bool {
+	return isSafeFilename(fl.Field().String())
+}
+
+func isSafeFilename(value string) bool {
+	if value == "" || len(value) > 255 || value == "." || value == ".." {
+		return false
+	}
+	if strings.TrimSpace(value) != value || strings.HasSuffix(value, ".") {
+		return false
+	}
+	for _, r := range value {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(`/\<>:"|?*`, r) {
+			return false
+		}
+	}
+	name := strings.ToUpper(value)
+	if index := strings.IndexByte(name, '.'); index >= 0 {
+		name = name[:index]
+	}
+	for _, reserved := range reservedFilenames {
+		if name == reserved {
+			return false
+		}
+	}
+	return true
+}
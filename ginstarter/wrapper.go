@@ -3,6 +3,7 @@ package ginstarter
 import (
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/acexy/golang-toolkit/logger"
 	"github.com/gin-gonic/gin"
@@ -11,6 +12,49 @@ import (
 // RouterWrapper 定义路由包装器
 type RouterWrapper struct {
 	routerGroup *gin.RouterGroup
+	// 请求超时时间
+	timeout time.Duration
+	// 请求优先级
+	priority RequestPriority
+	// 单个路由的并发限制
+	concurrencyLimit *ConcurrencyLimitConfig
+	// 访问授权要求
+	authorizations []*Authorization
+}
+
+// WithTimeout 设置通过返回的RouterWrapper注册的路由的请求超时时间 覆盖RouterInfo及全局配置 0表示继承 负数表示禁用
+//
+//	router.WithTimeout(time.Second * 3).GET("slow", handler)
+func (r *RouterWrapper) WithTimeout(timeout time.Duration) *RouterWrapper {
+	wrapper := *r
+	wrapper.timeout = resolveTimeout(timeout, r.timeout)
+	return &wrapper
+}
+
+// WithPriority 设置通过返回的RouterWrapper注册的路由的请求优先级 覆盖RouterInfo配置
+func (r *RouterWrapper) WithPriority(priority RequestPriority) *RouterWrapper {
+	wrapper := *r
+	wrapper.priority = priority
+	return &wrapper
+}
+
+// WithConcurrencyLimit 设置通过返回的RouterWrapper注册的每个路由独立的并发限制
+//
+//	router.WithConcurrencyLimit(&ginstarter.ConcurrencyLimitConfig{MaxConcurrency: 10}).GET("export", handler)
+func (r *RouterWrapper) WithConcurrencyLimit(config *ConcurrencyLimitConfig) *RouterWrapper {
+	wrapper := *r
+	wrapper.concurrencyLimit = config
+	return &wrapper
+}
+
+// WithAuthorization 为通过返回的RouterWrapper注册的路由追加访问授权要求 与RouterInfo的授权要求需同时满足
+//
+//	router.WithAuthorization(ginstarter.RequireAnyRole("admin", "ops")).DELETE("order/:id", handler)
+//	router.WithAuthorization(ginstarter.RequirePermissions("project:{id}:write")).PUT("project/:id", handler)
+func (r *RouterWrapper) WithAuthorization(authorization *Authorization) *RouterWrapper {
+	wrapper := *r
+	wrapper.authorizations = append(append([]*Authorization{}, r.authorizations...), authorization)
+	return &wrapper
 }
 
 // HandlerWrapper 定义内部Handler
@@ -77,6 +121,7 @@ func (r *RouterWrapper) MATCH1(method []string, path string, contentType []strin
 
 func (r *RouterWrapper) handler(methods []string, path string, contentType []string, handlerWrapper ...HandlerWrapper) {
 	handlers := make([]gin.HandlerFunc, len(handlerWrapper))
+	timeout := r.timeout
 	for i, handler := range handlerWrapper {
 		handlers[i] = func(context *gin.Context) {
 			v, exists := context.Get(ginCtxKeyContinueHandler)
@@ -95,8 +140,28 @@ func (r *RouterWrapper) handler(methods []string, path string, contentType []str
 					})
 				}
 			}
-			response, err := handler(&Request{context})
+			request := &Request{context}
+			var response Response
+			var err error
+			if timeout > 0 {
+				var abandoned error
+				response, err, abandoned = invokeWithTimeout(request, handler, timeout)
+				if abandoned != nil {
+					// 客户端已断开时不再响应
+					if errors.Is(abandoned, errHandlerTimeout) {
+						httpResponse(context, httpStatusResponse(request, http.StatusGatewayTimeout))
+					}
+					return
+				}
+			} else {
+				response, err = handler(request)
+			}
 			if err != nil {
+				// 业务错误及错误映射器匹配的错误 直接响应映射结果
+				if response, matched := mapHandlerError(request, err); matched {
+					httpResponse(context, response)
+					return
+				}
 				context.Status(http.StatusInternalServerError)
 				panic(err)
 			}
@@ -107,5 +172,12 @@ func (r *RouterWrapper) handler(methods []string, path string, contentType []str
 			}
 		}
 	}
+	registerRoute(methods, joinRoutePath(r.routerGroup.BasePath(), path), r)
+	if r.concurrencyLimit != nil {
+		handlers = append([]gin.HandlerFunc{newConcurrencyLimiter(r.concurrencyLimit).handler()}, handlers...)
+	}
+	if len(r.authorizations) > 0 {
+		handlers = append([]gin.HandlerFunc{authorizationHandler(r.authorizations)}, handlers...)
+	}
 	r.routerGroup.Match(methods, path, handlers...)
 }
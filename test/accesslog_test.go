@@ -0,0 +1,140 @@
+package test
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/acexy/golang-toolkit/logger"
+	"github.com/golang-acexy/starter-gin/ginstarter"
+	"github.com/golang-acexy/starter-gin/ginstarter/ginstartertest"
+	"github.com/golang-acexy/starter-gin/test/router"
+	"github.com/sirupsen/logrus"
+)
+
+type accessLogHook struct {
+	mutex   sync.Mutex
+	entries []*logrus.Entry
+}
+
+func (h *accessLogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *accessLogHook) Fire(entry *logrus.Entry) error {
+	if entry.Message == "access" || strings.HasPrefix(entry.Message, "GET ") {
+		h.mutex.Lock()
+		defer h.mutex.Unlock()
+		h.entries = append(h.entries, entry)
+	}
+	return nil
+}
+
+// 取出已记录的访问日志
+func (h *accessLogHook) take() []*logrus.Entry {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	entries := h.entries
+	h.entries = nil
+	return entries
+}
+
+func captureAccessLog(t *testing.T) *accessLogHook {
+	hook := &accessLogHook{}
+	hooks := logger.Logrus().ReplaceHooks(logrus.LevelHooks{})
+	logger.Logrus().AddHook(hook)
+	t.Cleanup(func() {
+		logger.Logrus().ReplaceHooks(hooks)
+	})
+	return hook
+}
+
+func TestAccessLogFields(t *testing.T) {
+	hook := captureAccessLog(t)
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		RequestId: &ginstarter.RequestIdConfig{},
+		AccessLog: &ginstarter.AccessLogConfig{
+			ExcludePaths:  []string{"/demo/empty", "/demo/error*"},
+			SlowThreshold: time.Hour,
+			Fields: func(request *ginstarter.Request) map[string]any {
+				return map[string]any{"tenant": request.GetHeader("X-Tenant")}
+			},
+		},
+		Routers: []ginstarter.Router{&router.DemoRouter{}},
+	})
+
+	harness.Get("/demo/common").RemoteAddr("203.0.113.9:1234").
+		Header("X-Forwarded-For", "127.0.0.1").
+		Header("X-Tenant", "acexy").
+		Header("User-Agent", "ginstartertest").Do().AssertHttpStatus(http.StatusOK)
+	entries := hook.take()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 access log, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Level != logrus.InfoLevel {
+		t.Errorf("unexpected level: %s", entry.Level)
+	}
+	expected := map[string]any{
+		"method":     http.MethodGet,
+		"route":      "/demo/common",
+		"path":       "/demo/common",
+		"status":     http.StatusOK,
+		"client_ip":  "203.0.113.9",
+		"user_agent": "ginstartertest",
+		"tenant":     "acexy",
+	}
+	for key, value := range expected {
+		if entry.Data[key] != value {
+			t.Errorf("field %s: expected %v actual %v", key, value, entry.Data[key])
+		}
+	}
+	if entry.Data["request_id"] == "" || entry.Data["request_id"] == nil {
+		t.Error("request_id not logged")
+	}
+	if _, ok := entry.Data["slow"]; ok {
+		t.Error("fast request logged as slow")
+	}
+
+	// 被BadHttpCodeResolver包裹的响应记录原始状态码
+	harness.Get("/not-found").Do().AssertStatusCode(ginstarter.StatusCodeNotFound)
+	entries = hook.take()
+	if len(entries) != 1 || entries[0].Data["status"] != http.StatusNotFound || entries[0].Data["http_status"] != http.StatusOK ||
+		entries[0].Data["route"] != "" {
+		t.Errorf("unexpected access log for unmatched route: %v", entries)
+	}
+
+	// 排除的路径
+	harness.Get("/demo/empty").Do()
+	harness.Get("/demo/error4").Do()
+	if entries = hook.take(); len(entries) != 0 {
+		t.Errorf("excluded paths logged: %d", len(entries))
+	}
+}
+
+// 慢请求提升至Warn级别 文本格式输出
+func TestAccessLogSlowText(t *testing.T) {
+	hook := captureAccessLog(t)
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		AccessLog: &ginstarter.AccessLogConfig{
+			Format:        ginstarter.AccessLogText,
+			SlowThreshold: time.Nanosecond,
+		},
+		Routers: []ginstarter.Router{&router.DemoRouter{}},
+	})
+	harness.Get("/demo/common").RemoteAddr("203.0.113.9:1234").Do().AssertHttpStatus(http.StatusOK)
+	entries := hook.take()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 access log, got %d", len(entries))
+	}
+	if entries[0].Level != logrus.WarnLevel {
+		t.Errorf("slow request level: %s", entries[0].Level)
+	}
+	message := entries[0].Message
+	if !strings.HasPrefix(message, "GET /demo/common /demo/common 200 ") || !strings.Contains(message, " 203.0.113.9 ") ||
+		!strings.HasSuffix(message, " slow=true") {
+		t.Errorf("unexpected access log: %s", message)
+	}
+}
@@ -0,0 +1,70 @@
+package test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+	"github.com/golang-acexy/starter-gin/test/router"
+)
+
+// 未设置任何访问保护时拒绝启动
+func TestAdminRequiresProtection(t *testing.T) {
+	_, closeFn, err := (&ginstarter.GinStarter{Config: ginstarter.GinConfig{
+		Admin: &ginstarter.AdminConfig{},
+	}}).BuildEngine()
+	if err == nil {
+		closeFn()
+		t.Fatal("expected error for unprotected admin endpoints")
+	}
+}
+
+// 等待端口可重新监听
+func assertPortReleased(t *testing.T, address string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		listener, err := net.Listen("tcp", address)
+		if err == nil {
+			_ = listener.Close()
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s is still in use: %v", address, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// 启动失败时关闭已启动的独立诊断及指标服务
+func TestAdminReleasedOnStartFailure(t *testing.T) {
+	adminAddress, metricsAddress := "127.0.0.1:16061", "127.0.0.1:16062"
+	_, closeFn, err := (&ginstarter.GinStarter{Config: ginstarter.GinConfig{
+		Admin:   &ginstarter.AdminConfig{ListenAddress: adminAddress},
+		Metrics: &ginstarter.MetricsConfig{ListenAddress: metricsAddress},
+		// 同步令牌模式未启用会话 构建失败
+		Routers: []ginstarter.Router{&router.SessionRouter{}},
+	}}).BuildEngine()
+	if err == nil {
+		closeFn()
+		t.Fatal("expected build error")
+	}
+	assertPortReleased(t, adminAddress)
+	assertPortReleased(t, metricsAddress)
+
+	occupied, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = occupied.Close()
+	}()
+	if _, err = (&ginstarter.GinStarter{Config: ginstarter.GinConfig{
+		ListenAddress: occupied.Addr().String(),
+		Admin:         &ginstarter.AdminConfig{ListenAddress: adminAddress},
+	}}).Start(); err == nil {
+		t.Fatal("expected listen error")
+	}
+	assertPortReleased(t, adminAddress)
+}
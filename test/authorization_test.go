@@ -0,0 +1,84 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+	"github.com/golang-acexy/starter-gin/ginstarter/ginstartertest"
+	"github.com/golang-acexy/starter-gin/test/router"
+)
+
+func TestMatchPermission(t *testing.T) {
+	cases := []struct {
+		granted  string
+		required string
+		expected bool
+	}{
+		{"order:1:read", "order:1:read", true},
+		{"*", "order:1:read", true},
+		{"order:*", "order:1:read", true},
+		{"order:*", "order", false},
+		{"order:*:read", "order:1:read", true},
+		{"order:*:read", "order:1:write", false},
+		{"order:*:read", "order:1:read:all", false},
+		{"order:1", "order:1:read", false},
+		{"order:1:read", "order:1", false},
+		{"order:1:read", "order:2:read", false},
+		{"user:*", "order:1:read", false},
+	}
+	for _, c := range cases {
+		if actual := ginstarter.MatchPermission(c.granted, c.required); actual != c.expected {
+			t.Errorf("MatchPermission(%q, %q): expected %v actual %v", c.granted, c.required, c.expected, actual)
+		}
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		Routers: []ginstarter.Router{&router.AuthorizationRouter{}},
+	})
+	user := func(method, path, roles, permissions string) *ginstartertest.Response {
+		return harness.Request(method, path).Header("X-User", "acexy").Header("X-Roles", roles).Header("X-Permissions", permissions).Do()
+	}
+	// 未认证401 已认证但授权不通过403
+	harness.Get("/authz/me").Do().AssertStatusCode(ginstarter.StatusCodeUnauthorized)
+	user("GET", "/authz/me", "", "").AssertStatusCode(ginstarter.StatusCodeForbidden)
+	user("GET", "/authz/me", "member", "").AssertSuccess().AssertData(`"acexy"`)
+
+	// RequireAnyRole满足任意一个即可 RequireRoles需全部满足
+	user("GET", "/authz/any", "member,ops", "").AssertSuccess()
+	user("GET", "/authz/any", "member", "").AssertStatusCode(ginstarter.StatusCodeForbidden)
+	user("GET", "/authz/all", "member,ops", "").AssertStatusCode(ginstarter.StatusCodeForbidden)
+	user("GET", "/authz/all", "member,admin,ops", "").AssertSuccess()
+	// WithAuthorization与RouterInfo的授权要求需同时满足
+	user("GET", "/authz/any", "admin", "").AssertStatusCode(ginstarter.StatusCodeForbidden)
+	user("GET", "/authz/all", "admin,ops", "").AssertStatusCode(ginstarter.StatusCodeForbidden)
+
+	// 权限中的{id}使用路径参数替换
+	user("PUT", "/authz/project/1", "member", "project:1:write").AssertSuccess()
+	user("PUT", "/authz/project/2", "member", "project:1:write").AssertStatusCode(ginstarter.StatusCodeForbidden)
+	user("PUT", "/authz/project/2", "member", "project:*:write").AssertSuccess()
+	user("PUT", "/authz/project/2", "member", "project:*:read").AssertStatusCode(ginstarter.StatusCodeForbidden)
+}
+
+type failingAuthorizer struct {
+}
+
+func (f failingAuthorizer) HasRole(*ginstarter.Request, ginstarter.Principal, string) (bool, error) {
+	return false, errors.New("permission center unavailable")
+}
+
+func (f failingAuthorizer) HasPermission(*ginstarter.Request, ginstarter.Principal, string) (bool, error) {
+	return false, errors.New("permission center unavailable")
+}
+
+// 自定义Authorizer出错时响应500
+func TestAuthorizerError(t *testing.T) {
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		Authorizer: failingAuthorizer{},
+		Routers:    []ginstarter.Router{&router.AuthorizationRouter{}},
+	})
+	harness.Get("/authz/me").Header("X-User", "acexy").Header("X-Roles", "member").Do().
+		AssertStatusCode(ginstarter.StatusCodeException)
+}
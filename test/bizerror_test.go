@@ -0,0 +1,63 @@
+package test
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+	"github.com/golang-acexy/starter-gin/ginstarter/ginstartertest"
+	"github.com/golang-acexy/starter-gin/test/router"
+	"github.com/sirupsen/logrus"
+)
+
+func TestBizErrorHttpStatusCode(t *testing.T) {
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		DisableDefaultIgnoreHttpCode: true,
+		Routers:                      []ginstarter.Router{&router.DemoRouter{}},
+	})
+	// 非200状态码不由BadHttpCodeResolver改写 保留业务错误码
+	response := harness.Get("/demo/error5").Do().
+		AssertHttpStatus(http.StatusNotFound).
+		AssertBizErrorCode(router.ErrUserNotFound.Code())
+	if message := bizErrorMessage(response); message != "user acexy not found" {
+		t.Errorf("unexpected biz error message: %s", message)
+	}
+	harness.Get("/demo/error6").Do().
+		AssertHttpStatus(http.StatusConflict).
+		AssertBizErrorCode(router.ErrOrderNotFound.Code())
+	// 已注册的业务错误不受影响
+	harness.Get("/demo/error4").Do().
+		AssertHttpStatus(http.StatusOK).
+		AssertBizErrorCode(router.ErrOrderNotFound.Code())
+}
+
+// 设置方法返回副本 并发调用不修改已注册的业务错误
+func TestBizErrorSetters(t *testing.T) {
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		Routers: []ginstarter.Router{&router.DemoRouter{}},
+	})
+	wg := sync.WaitGroup{}
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			copied := router.ErrOrderNotFound.
+				SetStatusCode(ginstarter.StatusCodeNotFound).
+				SetHttpStatusCode(http.StatusNotFound).
+				SetLogLevel(logrus.WarnLevel)
+			if copied == router.ErrOrderNotFound || copied.Code() != router.ErrOrderNotFound.Code() {
+				t.Error("setter should return a copy with the same code")
+			}
+		}()
+	}
+	wg.Wait()
+	registered, ok := ginstarter.LookupBizError(router.ErrOrderNotFound.Code())
+	if !ok || registered != router.ErrOrderNotFound {
+		t.Fatal("registered biz error not found")
+	}
+	status := harness.Get("/demo/error4").Do().AssertHttpStatus(http.StatusOK).Rest().Status
+	if status.StatusCode != ginstarter.StatusCodeSuccess {
+		t.Errorf("registered biz error changed status code: %d", status.StatusCode)
+	}
+}
@@ -0,0 +1,249 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/acexy/golang-toolkit/logger"
+	"github.com/golang-acexy/starter-gin/ginstarter"
+	"github.com/golang-acexy/starter-gin/ginstarter/ginstartertest"
+	"github.com/golang-acexy/starter-gin/test/router"
+	"github.com/sirupsen/logrus"
+)
+
+type queuedHook struct {
+	queued chan struct{}
+}
+
+func (h *queuedHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.DebugLevel}
+}
+
+func (h *queuedHook) Fire(entry *logrus.Entry) error {
+	if strings.HasPrefix(entry.Message, "request queued") {
+		h.queued <- struct{}{}
+	}
+	return nil
+}
+
+// 通过排队时的调试日志感知请求已进入等待队列
+func captureQueued(t *testing.T) <-chan struct{} {
+	hook := &queuedHook{queued: make(chan struct{}, 64)}
+	level := logger.Logrus().GetLevel()
+	hooks := logger.Logrus().ReplaceHooks(logrus.LevelHooks{})
+	logger.Logrus().AddHook(hook)
+	logger.Logrus().SetLevel(logrus.DebugLevel)
+	t.Cleanup(func() {
+		logger.Logrus().SetLevel(level)
+		logger.Logrus().ReplaceHooks(hooks)
+	})
+	return hook.queued
+}
+
+func newConcurrencyHarness(t *testing.T, config *ginstarter.ConcurrencyLimitConfig) (*ginstartertest.Harness, *router.ConcurrencyRouter) {
+	concurrencyRouter := &router.ConcurrencyRouter{Started: make(chan string, 64), Release: make(chan struct{})}
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		ConcurrencyLimit: config,
+		Routers:          []ginstarter.Router{concurrencyRouter},
+	})
+	return harness, concurrencyRouter
+}
+
+// 异步发起请求 返回响应的channel
+func doAsync(harness *ginstartertest.Harness, path, id string, hold bool) <-chan *ginstartertest.Response {
+	builder := harness.Get(path).Query("id", id)
+	if hold {
+		builder.Query("hold", "")
+	}
+	done := make(chan *ginstartertest.Response, 1)
+	go func() {
+		done <- builder.Do()
+	}()
+	return done
+}
+
+func TestConcurrencyLimitQueue(t *testing.T) {
+	queued := captureQueued(t)
+	harness, concurrencyRouter := newConcurrencyHarness(t, &ginstarter.ConcurrencyLimitConfig{
+		MaxConcurrency: 1,
+		MaxWait:        time.Minute,
+		MaxQueue:       1,
+		RetryAfter:     1500 * time.Millisecond,
+	})
+	holder := doAsync(harness, "/concurrency/normal", "a", true)
+	<-concurrencyRouter.Started
+	waiter := doAsync(harness, "/concurrency/normal", "b", false)
+	<-queued
+	// 队列已满时立即拒绝 Retry-After向上取整
+	harness.Get("/concurrency/normal").Do().
+		AssertStatusCode(ginstarter.StatusCodeServiceUnavailable).
+		AssertHeader("Retry-After", "2")
+	close(concurrencyRouter.Release)
+	(<-holder).AssertSuccess().AssertData(`"a"`)
+	(<-waiter).AssertSuccess().AssertData(`"b"`)
+}
+
+func TestConcurrencyLimitMaxWait(t *testing.T) {
+	queued := captureQueued(t)
+	harness, concurrencyRouter := newConcurrencyHarness(t, &ginstarter.ConcurrencyLimitConfig{
+		MaxConcurrency: 1,
+		MaxWait:        time.Millisecond,
+	})
+	holder := doAsync(harness, "/concurrency/normal", "a", true)
+	<-concurrencyRouter.Started
+	// 排队超过MaxWait仍未获取额度时拒绝
+	waiter := doAsync(harness, "/concurrency/normal", "b", false)
+	<-queued
+	(<-waiter).AssertStatusCode(ginstarter.StatusCodeServiceUnavailable).AssertHeader("Retry-After", "1")
+	close(concurrencyRouter.Release)
+	(<-holder).AssertSuccess()
+	harness.Get("/concurrency/normal").Do().AssertSuccess()
+}
+
+func TestConcurrencyLimitLowPriority(t *testing.T) {
+	harness, concurrencyRouter := newConcurrencyHarness(t, &ginstarter.ConcurrencyLimitConfig{
+		MaxConcurrency:   5,
+		MaxWait:          time.Minute,
+		LowPriorityRatio: 0.4,
+	})
+	var holders []<-chan *ginstartertest.Response
+	holders = append(holders, doAsync(harness, "/concurrency/low", "1", true))
+	<-concurrencyRouter.Started
+	holders = append(holders, doAsync(harness, "/concurrency/normal", "2", true))
+	<-concurrencyRouter.Started
+	// 低优先级请求仅可使用40%的额度 且不参与排队
+	harness.Get("/concurrency/low").Do().AssertStatusCode(ginstarter.StatusCodeServiceUnavailable)
+	holders = append(holders, doAsync(harness, "/concurrency/normal", "3", true))
+	<-concurrencyRouter.Started
+	close(concurrencyRouter.Release)
+	for _, holder := range holders {
+		(<-holder).AssertSuccess()
+	}
+}
+
+func TestConcurrencyLimitHighPriorityFirst(t *testing.T) {
+	queued := captureQueued(t)
+	harness, concurrencyRouter := newConcurrencyHarness(t, &ginstarter.ConcurrencyLimitConfig{
+		MaxConcurrency: 1,
+		MaxWait:        time.Minute,
+		MaxQueue:       4,
+	})
+	holder := doAsync(harness, "/concurrency/normal", "holder", true)
+	<-concurrencyRouter.Started
+	normal := doAsync(harness, "/concurrency/normal", "normal", false)
+	<-queued
+	high := doAsync(harness, "/concurrency/high", "high", false)
+	<-queued
+	close(concurrencyRouter.Release)
+	for _, response := range []<-chan *ginstartertest.Response{holder, normal, high} {
+		(<-response).AssertSuccess()
+	}
+	// 高优先级请求先于更早排队的普通请求获取额度
+	if first, second := <-concurrencyRouter.Started, <-concurrencyRouter.Started; first != "high" || second != "normal" {
+		t.Errorf("admission order: expected [high normal] actual [%s %s]", first, second)
+	}
+}
+
+// 同时持有两个请求 返回第二个请求是否获取到额度
+func admitsTwo(harness *ginstartertest.Harness, concurrencyRouter *router.ConcurrencyRouter) bool {
+	release := make(chan struct{})
+	concurrencyRouter.Release = release
+	first := doAsync(harness, "/concurrency/normal", "1", true)
+	<-concurrencyRouter.Started
+	second := doAsync(harness, "/concurrency/normal", "2", true)
+	admitted := false
+	select {
+	case <-concurrencyRouter.Started:
+		admitted = true
+	case response := <-second:
+		response.AssertStatusCode(ginstarter.StatusCodeServiceUnavailable)
+	}
+	close(release)
+	<-first
+	if admitted {
+		<-second
+	}
+	return admitted
+}
+
+func TestConcurrencyLimitAIMD(t *testing.T) {
+	// 请求耗时均超过阈值 每次释放时并发限制减半
+	harness, concurrencyRouter := newConcurrencyHarness(t, &ginstarter.ConcurrencyLimitConfig{
+		MaxConcurrency: 2,
+		Adaptive: &ginstarter.AdaptiveLimitConfig{
+			LatencyThreshold: time.Nanosecond,
+			BackoffRatio:     0.5,
+		},
+	})
+	if !admitsTwo(harness, concurrencyRouter) {
+		t.Fatal("initial limit 2 rejected the second request")
+	}
+	if admitsTwo(harness, concurrencyRouter) {
+		t.Error("limit not reduced after slow requests")
+	}
+
+	// 请求耗时未超过阈值且并发达到限制的一半时加性增加
+	harness, concurrencyRouter = newConcurrencyHarness(t, &ginstarter.ConcurrencyLimitConfig{
+		MaxConcurrency: 1,
+		Adaptive:       &ginstarter.AdaptiveLimitConfig{LatencyThreshold: time.Hour},
+	})
+	if admitsTwo(harness, concurrencyRouter) {
+		t.Fatal("initial limit 1 admitted the second request")
+	}
+	if !admitsTwo(harness, concurrencyRouter) {
+		t.Error("limit not increased after fast requests")
+	}
+}
+
+func TestConcurrencyLimitGradient(t *testing.T) {
+	harness, concurrencyRouter := newConcurrencyHarness(t, &ginstarter.ConcurrencyLimitConfig{
+		MaxConcurrency: 1,
+		Adaptive:       &ginstarter.AdaptiveLimitConfig{Algorithm: ginstarter.AdaptiveLimitGradient},
+	})
+	if admitsTwo(harness, concurrencyRouter) {
+		t.Fatal("initial limit 1 admitted the second request")
+	}
+	// 并发限制较小时 无论耗时如何每次释放都会增加 20次后必然超过2
+	for i := 0; i < 20; i++ {
+		harness.Get("/concurrency/normal").Do().AssertSuccess()
+		<-concurrencyRouter.Started
+	}
+	if !admitsTwo(harness, concurrencyRouter) {
+		t.Error("gradient limit not increased")
+	}
+}
+
+// 排队请求超时或取消的同时被分配额度 额度不能泄漏
+func TestConcurrencyLimitAcquiredWhileCancelling(t *testing.T) {
+	queued := captureQueued(t)
+	for i := 0; i < 100; i++ {
+		harness, concurrencyRouter := newConcurrencyHarness(t, &ginstarter.ConcurrencyLimitConfig{
+			MaxConcurrency: 1,
+			MaxWait:        time.Minute,
+		})
+		holder := doAsync(harness, "/concurrency/normal", "holder", true)
+		<-concurrencyRouter.Started
+		ctx, cancel := context.WithCancel(context.Background())
+		request := httptest.NewRequest(http.MethodGet, "/concurrency/normal?id=waiter", nil).WithContext(ctx)
+		recorder := httptest.NewRecorder()
+		served := make(chan struct{})
+		go func() {
+			defer close(served)
+			harness.Engine().ServeHTTP(recorder, request)
+		}()
+		<-queued
+		cancel()
+		close(concurrencyRouter.Release)
+		(<-holder).AssertSuccess()
+		<-served
+		// 低优先级请求不排队 额度泄漏时将被立即拒绝
+		harness.Get("/concurrency/low").Do().AssertSuccess()
+		for len(concurrencyRouter.Started) > 0 {
+			<-concurrencyRouter.Started
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+	"github.com/golang-acexy/starter-gin/ginstarter/ginstartertest"
+	"github.com/golang-acexy/starter-gin/test/router"
+)
+
+func TestContextKey(t *testing.T) {
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		Routers: []ginstarter.Router{&router.ContextRouter{}},
+	})
+	harness.Get("/context/value").Do().AssertSuccess().
+		AssertData(`{"get":"t1","value":"t1","fallback":"t1","other":"","found":false}`)
+}
+
+// 服务停止时等待超时后取消未完成请求的上下文
+func TestRequestContextCancelledOnStop(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	_ = listener.Close()
+	contextRouter := &router.ContextRouter{Started: make(chan struct{}, 1), Cancelled: make(chan error, 1)}
+	starter := &ginstarter.GinStarter{Config: ginstarter.GinConfig{
+		ListenAddress: address,
+		Routers:       []ginstarter.Router{contextRouter},
+	}}
+	if _, err = starter.Start(); err != nil {
+		t.Fatal(err)
+	}
+	requested := make(chan struct{})
+	go func() {
+		defer close(requested)
+		if response, err := http.Get("http://" + address + "/context/wait"); err == nil {
+			_ = response.Body.Close()
+		}
+	}()
+	<-contextRouter.Started
+	gracefully, _, _ := starter.Stop(10 * time.Millisecond)
+	if gracefully {
+		t.Error("stop reported graceful with a pending request")
+	}
+	if err = <-contextRouter.Cancelled; err != context.Canceled {
+		t.Errorf("request context error: expected %v actual %v", context.Canceled, err)
+	}
+	<-requested
+}
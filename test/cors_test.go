@@ -0,0 +1,94 @@
+package test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+	"github.com/golang-acexy/starter-gin/ginstarter/ginstartertest"
+	"github.com/golang-acexy/starter-gin/test/router"
+)
+
+func corsHarness(t *testing.T, config *ginstarter.CorsConfig) *ginstartertest.Harness {
+	return ginstartertest.New(t, ginstarter.GinConfig{
+		// 不忽略204 preflight响应仍不被BadHttpCodeResolver改写
+		DisableDefaultIgnoreHttpCode: true,
+		CorsConfig:                   config,
+		Routers:                      []ginstarter.Router{&router.DemoRouter{}, &router.CorsRouter{}},
+	})
+}
+
+// preflight请求在路由匹配及405处理之前响应
+func TestCorsPreflight(t *testing.T) {
+	harness := corsHarness(t, &ginstarter.CorsConfig{AllowOrigins: []string{"*"}})
+	response := harness.Request(http.MethodOptions, "/cors/items/1").
+		Header("Origin", "https://www.acexy.cn").
+		Header("Access-Control-Request-Method", http.MethodDelete).
+		Header("Access-Control-Request-Headers", "Authorization").Do().
+		AssertHttpStatus(http.StatusNoContent).
+		AssertHeader("Access-Control-Allow-Origin", "https://www.acexy.cn").
+		AssertHeader("Access-Control-Allow-Credentials", "true").
+		AssertHeader("Access-Control-Allow-Methods", "GET, POST, DELETE").
+		AssertHeader("Access-Control-Allow-Headers", "Content-Type, Authorization").
+		AssertHeader("Access-Control-Max-Age", "3600")
+	if body := response.String(); body != "" {
+		t.Errorf("preflight response has body: %s", body)
+	}
+	// 未注册OPTIONS方法的全局配置路由
+	harness.Request(http.MethodOptions, "/demo/common").
+		Header("Origin", "https://example.com").
+		Header("Access-Control-Request-Method", http.MethodPost).
+		Header("Access-Control-Request-Headers", "X-Custom").Do().
+		AssertHttpStatus(http.StatusNoContent).
+		AssertHeader("Access-Control-Allow-Origin", "*").
+		AssertHeader("Access-Control-Allow-Headers", "X-Custom")
+	// 非preflight的OPTIONS请求仍由405处理
+	harness.Request(http.MethodOptions, "/demo/common").Header("Origin", "https://example.com").Do().
+		AssertStatusCode(ginstarter.StatusCodeMethodNotAllowed)
+}
+
+// Router配置覆盖全局配置
+func TestCorsOrigins(t *testing.T) {
+	harness := corsHarness(t, &ginstarter.CorsConfig{AllowOrigins: []string{"https://example.com"}})
+	cases := []struct {
+		path    string
+		origin  string
+		allowed bool
+	}{
+		{path: "/demo/common", origin: "https://example.com", allowed: true},
+		{path: "/demo/common", origin: "https://www.acexy.cn", allowed: false},
+		{path: "/cors/items", origin: "https://example.com", allowed: false},
+		{path: "/cors/items", origin: "https://www.acexy.cn", allowed: true},
+		{path: "/cors/items", origin: "https://a.b.acexy.cn", allowed: true},
+		{path: "/cors/items", origin: "https://acexy.cn.evil.com", allowed: false},
+		{path: "/cors/items", origin: "http://www.acexy.cn", allowed: false},
+		{path: "/cors/items", origin: "http://localhost:8080", allowed: true},
+		{path: "/cors/items", origin: "https://app-12.example.com", allowed: true},
+		{path: "/cors/items", origin: "https://app-x.example.com", allowed: false},
+	}
+	for _, c := range cases {
+		response := harness.Get(c.path).Header("Origin", c.origin).Do().AssertHttpStatus(http.StatusOK)
+		allowOrigin := response.Header().Get("Access-Control-Allow-Origin")
+		if c.allowed && allowOrigin != c.origin || !c.allowed && allowOrigin != "" {
+			t.Errorf("%s %s: unexpected Access-Control-Allow-Origin %q", c.path, c.origin, allowOrigin)
+		}
+	}
+	harness.Get("/cors/items").Header("Origin", "https://www.acexy.cn").Do().
+		AssertHeader("Access-Control-Expose-Headers", "X-Total-Count").
+		AssertHeader("Access-Control-Allow-Credentials", "true")
+	// 不允许的来源preflight响应403
+	harness.Request(http.MethodOptions, "/cors/items").
+		Header("Origin", "https://evil.com").
+		Header("Access-Control-Request-Method", http.MethodGet).Do().
+		AssertStatusCode(ginstarter.StatusCodeForbidden).
+		AssertHeader("Access-Control-Allow-Origin", "")
+}
+
+// 允许所有来源且携带凭证时回显请求来源而不是*
+func TestCorsCredentialsWithWildcard(t *testing.T) {
+	harness := corsHarness(t, &ginstarter.CorsConfig{AllowOrigins: []string{"*"}, AllowCredentials: true})
+	harness.Get("/demo/common").Header("Origin", "https://example.com").Do().
+		AssertHeader("Access-Control-Allow-Origin", "https://example.com").
+		AssertHeader("Access-Control-Allow-Credentials", "true").
+		AssertHeader("Vary", "Origin")
+}
@@ -0,0 +1,85 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+	"github.com/golang-acexy/starter-gin/ginstarter/ginstartertest"
+	"github.com/golang-acexy/starter-gin/test/router"
+)
+
+func TestCsrfSynchronizerToken(t *testing.T) {
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		SessionConfig: &ginstarter.SessionConfig{
+			Store:     ginstarter.NewMemorySessionStore(),
+			SecretKey: []byte("acexy"),
+		},
+		Routers: []ginstarter.Router{&router.SessionRouter{}},
+	})
+
+	// 匿名的安全请求不创建会话及token
+	response := harness.Get("/session/me").Do().AssertHeader("X-CSRF-Token", "")
+	if cookies := response.Cookies(); len(cookies) > 0 {
+		t.Errorf("anonymous request issued cookies: %v", cookies)
+	}
+	harness.Post("/session/profile").Form(map[string]string{"nickname": "acexy"}).Do().
+		AssertStatusCode(ginstarter.StatusCodeForbidden)
+
+	harness.Get("/session/login").Query("username", "acexy").Do().AssertSuccess()
+	token := harness.Get("/session/me").Do().AssertSuccess().Header().Get("X-CSRF-Token")
+	if token == "" {
+		t.Fatal("csrf token not exposed for existing session")
+	}
+	harness.Post("/session/profile").Form(map[string]string{"nickname": "acexy"}).Do().
+		AssertStatusCode(ginstarter.StatusCodeForbidden)
+	// Origin需与请求的协议及Host一致
+	harness.Post("/session/profile").Header("X-CSRF-Token", token).Header("Origin", "https://example.com").
+		Form(map[string]string{"nickname": "acexy"}).Do().
+		AssertStatusCode(ginstarter.StatusCodeForbidden)
+	harness.Post("/session/profile").Header("X-CSRF-Token", token).Header("Origin", "http://evil.com").
+		Form(map[string]string{"nickname": "acexy"}).Do().
+		AssertStatusCode(ginstarter.StatusCodeForbidden)
+	harness.Post("/session/profile").Header("X-CSRF-Token", token).Header("Origin", "http://example.com").
+		Form(map[string]string{"nickname": "acexy"}).Do().
+		AssertSuccess()
+
+	// handler主动获取token时下发
+	harness.ClearCookies()
+	response = harness.Get("/session/csrf").Do().AssertSuccess()
+	if response.Header().Get("X-CSRF-Token") == "" || len(response.Cookies()) == 0 {
+		t.Error("csrf token not issued when requested by handler")
+	}
+}
+
+// 同步令牌模式未启用会话时启动失败 校验仅与本次构建注册的Router相关
+func TestCsrfSynchronizerTokenWithoutSession(t *testing.T) {
+	build := func(config ginstarter.GinConfig) error {
+		_, closeFn, err := (&ginstarter.GinStarter{Config: config}).BuildEngine()
+		if err == nil {
+			closeFn()
+		}
+		return err
+	}
+	withSession := ginstarter.GinConfig{
+		SessionConfig: &ginstarter.SessionConfig{
+			Store:     ginstarter.NewMemorySessionStore(),
+			SecretKey: []byte("acexy"),
+		},
+		Routers: []ginstarter.Router{&router.SessionRouter{}},
+	}
+	withoutSession := ginstarter.GinConfig{Routers: []ginstarter.Router{&router.SessionRouter{}}}
+
+	// 创建但未注册的拦截器不影响构建
+	_ = ginstarter.CsrfInterceptor(&ginstarter.CsrfConfig{Mode: ginstarter.CsrfSynchronizerToken})
+	if err := build(ginstarter.GinConfig{Routers: []ginstarter.Router{&router.DemoRouter{}}}); err != nil {
+		t.Fatalf("unregistered csrf interceptor failed the build: %v", err)
+	}
+	for range 2 {
+		if err := build(withoutSession); err == nil {
+			t.Fatal("expected error without session config")
+		}
+		if err := build(withSession); err != nil {
+			t.Fatalf("build with session config failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package test
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/acexy/golang-toolkit/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-acexy/starter-gin/ginstarter"
+	"github.com/golang-acexy/starter-gin/ginstarter/ginstartertest"
+	"github.com/sirupsen/logrus"
+)
+
+type dumpHook struct {
+	mutex    sync.Mutex
+	messages []string
+}
+
+func (h *dumpHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *dumpHook) Fire(entry *logrus.Entry) error {
+	if strings.HasPrefix(entry.Message, "dump ") {
+		h.mutex.Lock()
+		defer h.mutex.Unlock()
+		h.messages = append(h.messages, entry.Message)
+	}
+	return nil
+}
+
+func (h *dumpHook) last() string {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if len(h.messages) == 0 {
+		return ""
+	}
+	return h.messages[len(h.messages)-1]
+}
+
+// 字段名包含脱敏名称的JSON 表单及Query字段均被脱敏
+func TestDumpRedaction(t *testing.T) {
+	hook := &dumpHook{}
+	hooks := logger.Logrus().ReplaceHooks(logrus.LevelHooks{})
+	logger.Logrus().AddHook(hook)
+	defer logger.Logrus().ReplaceHooks(hooks)
+
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		DebugModule: true,
+		Dump:        &ginstarter.DumpConfig{RedactFields: []string{"pin"}},
+		InitFunc: func(instance *gin.Engine) {
+			instance.POST("/dump", func(context *gin.Context) {
+				context.JSON(http.StatusOK, gin.H{"access_token": "r-token-1", "name": "acexy"})
+			})
+		},
+	})
+	secrets := []string{"r-token-1", "q-token-2", "j-token-3", "j-pass-4", "j-secret-5", "f-pass-6", "f-pin-7", "j-key-8"}
+
+	harness.Post("/dump").Query("access_token", "q-token-2").Query("page", "1").JSON(map[string]any{
+		"access_token":  "j-token-3",
+		"newPassword":   "j-pass-4",
+		"client_secret": "j-secret-5",
+		"nested":        map[string]any{"apiToken": map[string]any{"value": "x"}, "privateSecretKey": "j-key-8"},
+		"name":          "acexy",
+	}).Do().AssertHttpStatus(http.StatusOK)
+	dump := hook.last()
+	harness.Post("/dump").Form(map[string]string{"oldPassword": "f-pass-6", "userPin": "f-pin-7", "name": "acexy"}).Do().
+		AssertHttpStatus(http.StatusOK)
+	dump += "\n" + hook.last()
+
+	if !strings.Contains(dump, "acexy") || !strings.Contains(dump, "page=1") {
+		t.Fatalf("unexpected dump: %s", dump)
+	}
+	for _, secret := range secrets {
+		if strings.Contains(dump, secret) {
+			t.Errorf("secret %s is not redacted: %s", secret, dump)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+	"github.com/golang-acexy/starter-gin/ginstarter/ginstartertest"
+	"github.com/golang-acexy/starter-gin/test/router"
+)
+
+func TestCommonErrorMappers(t *testing.T) {
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		ErrorMappers: ginstarter.CommonErrorMappers(),
+		Routers:      []ginstarter.Router{&router.ErrorMapperRouter{}},
+	})
+	cases := []struct {
+		kind     string
+		expected ginstarter.StatusCode
+	}{
+		{"no-rows", ginstarter.StatusCodeNotFound},
+		{"deadline", ginstarter.StatusCodeTimeout},
+		{"permission", ginstarter.StatusCodeForbidden},
+		{"not-exist", ginstarter.StatusCodeNotFound},
+		// 同时匹配多个映射器时按注册顺序使用第一个
+		{"joined", ginstarter.StatusCodeForbidden},
+	}
+	for _, c := range cases {
+		response := harness.Get("/mapper/" + c.kind).Do().AssertHttpStatus(http.StatusOK).AssertStatusCode(c.expected)
+		// 不暴露原始错误信息
+		if body := response.String(); strings.Contains(body, "/secret") || strings.Contains(body, "/missing") || strings.Contains(body, "sql:") || strings.Contains(body, "upstream") {
+			t.Errorf("%s: original error exposed: %s", c.kind, body)
+		}
+	}
+	// 未匹配的错误进入panic流程
+	harness.Get("/mapper/unknown").Do().AssertStatusCode(ginstarter.StatusCodeException)
+}
+
+func TestErrorMapperChain(t *testing.T) {
+	var visited []string
+	trace := func(name string) ginstarter.ErrorMapper {
+		return func(request *ginstarter.Request, err error) (ginstarter.Response, bool) {
+			visited = append(visited, name)
+			return nil, false
+		}
+	}
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		ErrorMappers: append([]ginstarter.ErrorMapper{
+			trace("first"),
+			ginstarter.ErrorAsMapper[*router.QuotaError](http.StatusTooManyRequests, "quota exceeded"),
+			ginstarter.ErrorMatchMapper(func(err error) bool {
+				return strings.Contains(err.Error(), "no rows")
+			}, func(request *ginstarter.Request, err error) ginstarter.Response {
+				return ginstarter.RespRestStatusError(ginstarter.StatusCodeNotFound, "user not found")
+			}),
+			trace("second"),
+		}, ginstarter.CommonErrorMappers()...),
+		Routers: []ginstarter.Router{&router.ErrorMapperRouter{}},
+	})
+
+	// ErrorAsMapper匹配包装后的错误类型 使用指定的错误信息
+	visited = nil
+	response := harness.Get("/mapper/quota").Do().AssertStatusCode(ginstarter.StatusCodeExceededLimit)
+	if message := response.Rest().Status.StatusMessage; message != "quota exceeded" {
+		t.Errorf("quota message: %s", message)
+	}
+	if strings.Join(visited, ",") != "first" {
+		t.Errorf("mappers after the matched one executed: %v", visited)
+	}
+
+	// 靠前的映射器优先于CommonErrorMappers
+	response = harness.Get("/mapper/no-rows").Do().AssertStatusCode(ginstarter.StatusCodeNotFound)
+	if message := response.Rest().Status.StatusMessage; message != "user not found" {
+		t.Errorf("no rows message: %s", message)
+	}
+
+	// 未匹配前面的映射器时依次执行
+	visited = nil
+	harness.Get("/mapper/deadline").Do().AssertStatusCode(ginstarter.StatusCodeTimeout)
+	if strings.Join(visited, ",") != "first,second" {
+		t.Errorf("mapper order: %v", visited)
+	}
+
+	// 业务错误优先于所有映射器
+	visited = nil
+	harness.Get("/mapper/biz").Do().AssertStatusCode(ginstarter.StatusCodeSuccess).AssertBizErrorCode(10002)
+	if len(visited) > 0 {
+		t.Errorf("mappers executed for biz error: %v", visited)
+	}
+}
@@ -61,11 +61,56 @@ func TestGinDefault(t *testing.T) {
 				ListenAddress:     ":8080",
 				UseReusePortModel: true,
 				DebugModule:       true,
+				ErrorMappers:      ginstarter.CommonErrorMappers(),
+				SessionConfig: &ginstarter.SessionConfig{
+					Store:     ginstarter.NewMemorySessionStore(),
+					SecretKey: []byte("acexy"),
+				},
+				CorsConfig: &ginstarter.CorsConfig{
+					AllowOrigins: []string{"*"},
+				},
+				AccessLog: &ginstarter.AccessLogConfig{
+					ExcludePaths:  []string{"/ping"},
+					SlowThreshold: time.Second,
+				},
+				// 请求响应转储 仅DebugModule下生效
+				Dump: &ginstarter.DumpConfig{
+					ExcludePaths: []string{"/ping", "/metrics", "/admin/*"},
+					MaxBodySize:  1024,
+				},
+				// 指标 GET /metrics
+				Metrics: &ginstarter.MetricsConfig{
+					ExcludePaths: []string{"/ping"},
+				},
+				RequestId: &ginstarter.RequestIdConfig{},
+				// 运维诊断端点 GET /admin/pprof/ /admin/config /admin/routes
+				Admin: &ginstarter.AdminConfig{
+					LoopbackOnly: true,
+					Guard: ginstarter.BasicAuthInterceptor(&ginstarter.BasicAuthAccount{
+						Username: "admin",
+						Password: "admin",
+					}),
+				},
+				Tracing: &ginstarter.TracingConfig{
+					ServiceName: "starter-gin-demo",
+				},
+				// Panic上报 可对接Sentry等异常收集服务
+				PanicReport: &ginstarter.PanicReportConfig{
+					Reporter: ginstarter.PanicReporterFunc(func(report *ginstarter.PanicReport) {
+						logger.Logrus().Warningln("panic reported:", report.Fingerprint, report.Method, report.Route, report.Error, "occurrences:", report.Occurrences)
+					}),
+				},
 				Routers: []ginstarter.Router{
 					&router.DemoRouter{},
 					&router.ParamRouter{},
 					&router.AbortRouter{},
 					&router.BasicAuthRouter{},
+					&router.RateLimitRouter{},
+					&router.JwtRouter{},
+					&router.SignatureRouter{},
+					&router.SessionRouter{},
+					&router.CorsRouter{},
+					&router.IPFilterRouter{},
 					&router.MyRestRouter{},
 				},
 				InitFunc: func(instance *gin.Engine) {
@@ -164,6 +209,36 @@ func TestGinCustomer(t *testing.T) {
 	sys.ShutdownHolding()
 }
 
+// 启用国际化 验证信息与默认状态信息根据请求语言响应
+func TestGinI18n(t *testing.T) {
+	starterLoader = parent.NewStarterLoader([]parent.Starter{
+		&ginstarter.GinStarter{
+			Config: ginstarter.GinConfig{
+				ListenAddress: ":8080",
+				DebugModule:   true,
+				Routers: []ginstarter.Router{
+					&router.I18nRouter{},
+				},
+				I18nConfig: &ginstarter.I18nConfig{
+					DefaultLocale:  ginstarter.LocaleZh,
+					QueryParamName: "lang",
+					CookieName:     "lang",
+					BizErrorMessages: map[string]map[ginstarter.BizErrorCode]ginstarter.BizErrorMessage{
+						ginstarter.LocaleEn: {router.BizErrorCodeOrderNotFound: "order {0} not found"},
+						ginstarter.LocaleZh: {router.BizErrorCodeOrderNotFound: "订单{0}不存在"},
+					},
+				},
+			},
+		},
+	})
+	err := starterLoader.Start()
+	if err != nil {
+		fmt.Printf("%+v\n", err)
+		return
+	}
+	sys.ShutdownHolding()
+}
+
 func TestGinLoadAndUnload(t *testing.T) {
 	starterLoader = parent.NewStarterLoader([]parent.Starter{
 		&ginstarter.GinStarter{
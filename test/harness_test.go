@@ -0,0 +1,156 @@
+package test
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-acexy/starter-gin/ginstarter"
+	"github.com/golang-acexy/starter-gin/ginstarter/ginstartertest"
+	"github.com/golang-acexy/starter-gin/test/router"
+)
+
+// 进程内测试 不监听端口 与Start使用相同的中间件及Resolver处理链
+func TestGinHarness(t *testing.T) {
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		ErrorMappers: ginstarter.CommonErrorMappers(),
+		SessionConfig: &ginstarter.SessionConfig{
+			Store:     ginstarter.NewMemorySessionStore(),
+			SecretKey: []byte("acexy"),
+		},
+		RequestId: &ginstarter.RequestIdConfig{},
+		Routers: []ginstarter.Router{
+			&router.DemoRouter{},
+			&router.BasicAuthRouter{},
+			&router.SessionRouter{},
+		},
+		InitFunc: func(instance *gin.Engine) {
+			instance.GET("/ping", func(context *gin.Context) {
+				context.String(http.StatusOK, "alive")
+			})
+		},
+	})
+
+	if body := harness.Get("/ping").Do().AssertHttpStatus(http.StatusOK).String(); body != "alive" {
+		t.Errorf("unexpected ping response: %s", body)
+	}
+	harness.Get("/not-found").Do().
+		AssertHttpStatus(http.StatusOK).
+		AssertStatusCode(ginstarter.StatusCodeNotFound)
+	harness.Get("/demo/error2").Do().
+		AssertStatusCode(ginstarter.StatusCodeException)
+	harness.Get("/demo/error4").Do().
+		AssertStatusCode(ginstarter.StatusCodeSuccess).
+		AssertBizErrorCode(10002)
+
+	harness.Get("/auth/invoke").Do().AssertStatusCode(ginstarter.StatusCodeUnauthorized)
+	harness.Get("/auth/invoke").BasicAuth("acexy", "acexy").Do().AssertHttpStatus(http.StatusOK)
+
+	// 会话Cookie自动保存并在后续请求中携带
+	harness.Get("/session/me").Do().AssertStatusCode(ginstarter.StatusCodeUnauthorized)
+	harness.Get("/session/login").Query("username", "acexy").Do().AssertSuccess()
+	harness.Get("/session/me").Do().
+		AssertSuccess().
+		AssertData(`{"user":{"username":"acexy"},"flashes":["welcome"]}`)
+	harness.Get("/session/logout").Do().AssertSuccess()
+	harness.Get("/session/me").Do().AssertStatusCode(ginstarter.StatusCodeUnauthorized)
+}
+
+// 响应快照及OpenAPI契约测试 使用 go test -update-golden 更新快照
+func TestGinContract(t *testing.T) {
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		ErrorMappers: ginstarter.CommonErrorMappers(),
+		RequestId:    &ginstarter.RequestIdConfig{},
+		Routers: []ginstarter.Router{
+			&router.DemoRouter{},
+			&router.BasicAuthRouter{},
+		},
+	})
+
+	harness.Get("/demo/error4").Do().AssertGolden("demo/error4")
+	harness.Get("/demo/common").Do().AssertGolden("demo/common")
+	harness.Get("/auth/invoke").Do().AssertGolden("auth/invoke-unauthorized")
+
+	// 根据路由表生成文档 并声明具体接口的响应Schema
+	doc := ginstartertest.GenerateOpenAPI("starter-gin-demo", "1.0.0").
+		SetResponse(http.MethodGet, "/auth/invoke", "200", nil)
+	doc.Paths["/auth/invoke"]["get"].Responses["200"].Content = map[string]*ginstartertest.MediaType{"text/plain": {}}
+	doc.Paths["/demo/empty"]["get"].Responses["200"] = &ginstartertest.OpenAPIResponse{Description: "empty response"}
+	if err := doc.WriteFile(filepath.Join(t.TempDir(), "openapi.json")); err != nil {
+		t.Fatal(err)
+	}
+	harness.Get("/demo/error4").Do().AssertOpenAPI(doc)
+	harness.Get("/demo/empty").Do().AssertOpenAPI(doc)
+	harness.Get("/auth/invoke").BasicAuth("acexy", "acexy").Do().AssertOpenAPI(doc)
+	harness.Get("/demo/error4").Do().AssertSchema(ginstartertest.RestSchema(nil))
+}
+
+// 记录断言失败而不终止测试
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// 快照不存在时断言失败 不自动创建快照
+func TestGinGoldenMissing(t *testing.T) {
+	if f := flag.Lookup("update-golden"); f != nil && f.Value.String() == "true" {
+		t.Skip("golden files are written with -update-golden")
+	}
+	recorder := &recordingT{TB: t}
+	harness := ginstartertest.New(recorder, ginstarter.GinConfig{
+		Routers: []ginstarter.Router{&router.DemoRouter{}},
+	})
+	dir := t.TempDir()
+	harness.Get("/demo/common").Do().AssertGolden("demo/common", ginstartertest.GoldenDir(dir))
+	if len(recorder.errors) != 1 || !strings.Contains(recorder.errors[0], "not found") {
+		t.Fatalf("missing golden file should fail the assertion, got %v", recorder.errors)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "demo_common.golden")); !os.IsNotExist(err) {
+		t.Fatalf("missing golden file should not be created, stat error: %v", err)
+	}
+}
+
+// Cookie按照Path及Secure属性携带
+func TestGinHarnessCookies(t *testing.T) {
+	echo := func(context *gin.Context) {
+		names := make([]string, 0)
+		for _, cookie := range context.Request.Cookies() {
+			names = append(names, cookie.Name)
+		}
+		slices.Sort(names)
+		context.String(http.StatusOK, strings.Join(names, ","))
+	}
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		InitFunc: func(instance *gin.Engine) {
+			instance.GET("/cookie/set", func(context *gin.Context) {
+				http.SetCookie(context.Writer, &http.Cookie{Name: "plain", Value: "1", Path: "/"})
+				http.SetCookie(context.Writer, &http.Cookie{Name: "scoped", Value: "1", Path: "/cookie/scoped"})
+				http.SetCookie(context.Writer, &http.Cookie{Name: "secure", Value: "1", Path: "/", Secure: true})
+			})
+			instance.GET("/echo", echo)
+			instance.GET("/cookie/scoped/echo", echo)
+		},
+	})
+	harness.Get("https://example.com/cookie/set").Do().AssertHttpStatus(http.StatusOK)
+	cases := map[string]string{
+		"/echo":                          "plain",
+		"/cookie/scoped/echo":            "plain,scoped",
+		"https://example.com/echo":       "plain,secure",
+		"https://other.example.org/echo": "",
+	}
+	for path, expected := range cases {
+		if actual := harness.Get(path).Do().String(); actual != expected {
+			t.Errorf("%s: expected cookies %q actual %q", path, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+	"github.com/golang-acexy/starter-gin/ginstarter/ginstartertest"
+	"github.com/golang-acexy/starter-gin/test/router"
+)
+
+func i18nHarness(t *testing.T) *ginstartertest.Harness {
+	return ginstartertest.New(t, ginstarter.GinConfig{
+		Routers: []ginstarter.Router{&router.I18nRouter{}},
+		I18nConfig: &ginstarter.I18nConfig{
+			DefaultLocale:  ginstarter.LocaleZh,
+			QueryParamName: "lang",
+			CookieName:     "lang",
+			BizErrorMessages: map[string]map[ginstarter.BizErrorCode]ginstarter.BizErrorMessage{
+				ginstarter.LocaleEn: {
+					router.BizErrorCodeOrderNotFound: "order {0} not found",
+					router.ErrOrderNotFound.Code():   "order {0} does not exist",
+				},
+				ginstarter.LocaleZh: {
+					router.BizErrorCodeOrderNotFound: "订单{0}不存在",
+					router.ErrOrderNotFound.Code():   "订单{0}未找到",
+				},
+			},
+		},
+	})
+}
+
+func bizErrorMessage(response *ginstartertest.Response) ginstarter.BizErrorMessage {
+	status := response.Rest().Status
+	if status.BizErrorMessage == nil {
+		return ""
+	}
+	return *status.BizErrorMessage
+}
+
+// 消息含占位符但未填充参数时 保留占位符而不是panic
+func TestI18nBizErrorWithoutParams(t *testing.T) {
+	harness := i18nHarness(t)
+	response := harness.Get("/i18n/order").Query("lang", "en").Do().
+		AssertStatusCode(ginstarter.StatusCodeSuccess).
+		AssertBizErrorCode(router.ErrOrderNotFound.Code())
+	if message := bizErrorMessage(response); message != "order {0} does not exist" {
+		t.Errorf("unexpected biz error message: %s", message)
+	}
+}
+
+// 语言解析优先级 query > cookie > Accept-Language > 默认语言
+func TestI18nLocaleResolution(t *testing.T) {
+	harness := i18nHarness(t)
+	cases := []struct {
+		name     string
+		query    string
+		cookie   string
+		accept   string
+		expected ginstarter.BizErrorMessage
+	}{
+		{name: "default", expected: "订单A001不存在"},
+		{name: "accept-language", accept: "en-US,en;q=0.9", expected: "order A001 not found"},
+		{name: "cookie over accept-language", cookie: "zh", accept: "en-US,en;q=0.9", expected: "订单A001不存在"},
+		{name: "query over cookie", query: "en", cookie: "zh", accept: "zh-CN,zh;q=0.9", expected: "order A001 not found"},
+		{name: "unsupported query", query: "fr", cookie: "en", expected: "order A001 not found"},
+	}
+	for _, c := range cases {
+		builder := harness.Get("/i18n/biz/A001")
+		if c.query != "" {
+			builder.Query("lang", c.query)
+		}
+		if c.cookie != "" {
+			builder.Cookie(&http.Cookie{Name: "lang", Value: c.cookie})
+		}
+		if c.accept != "" {
+			builder.Header("Accept-Language", c.accept)
+		}
+		response := builder.Do().AssertBizErrorCode(router.BizErrorCodeOrderNotFound)
+		if message := bizErrorMessage(response); message != c.expected {
+			t.Errorf("%s: expected %q actual %q", c.name, c.expected, message)
+		}
+	}
+}
+
+// 验证错误信息按请求语言翻译 字段名使用tag声明的名称
+func TestI18nValidator(t *testing.T) {
+	harness := i18nHarness(t)
+	form := map[string]string{"id": "1", "name": "acexy", "email": "bad", "nick_name": "acexy-acexy"}
+	cases := map[string]ginstarter.StatusMessage{
+		"en": "id must be 10 or greater; email must be a valid email address; domain must be a valid domain; nick_name must be a maximum of 8 characters in length",
+		"zh": "id最小只能为10; email必须是一个有效的邮箱; domain必须是一个有效的域名; nick_name长度不能超过8个字符",
+	}
+	for lang, expected := range cases {
+		status := harness.Post("/i18n/form").Query("lang", lang).Form(form).Do().
+			AssertStatusCode(ginstarter.StatusCodeBadRequestParameters).Rest().Status
+		if status.StatusMessage != expected {
+			t.Errorf("%s: expected %q actual %q", lang, expected, status.StatusMessage)
+		}
+	}
+}
+
+// 未启用国际化时验证错误信息的字段名保持不变
+func TestValidatorFieldNameWithoutI18n(t *testing.T) {
+	i18nHarness(t)
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		Routers: []ginstarter.Router{&router.I18nRouter{}},
+	})
+	form := map[string]string{"id": "10", "name": "acexy", "email": "acexy@example.com", "domain": "example.com", "home_page": "bad"}
+	status := harness.Post("/i18n/form").Form(form).Do().
+		AssertStatusCode(ginstarter.StatusCodeBadRequestParameters).Rest().Status
+	if status.StatusMessage != "homePage mismatch type url" {
+		t.Errorf("unexpected validator message: %s", status.StatusMessage)
+	}
+}
+
+// 默认状态信息按请求语言本地化 自定义信息保持不变
+func TestI18nStatusMessage(t *testing.T) {
+	harness := i18nHarness(t)
+	cases := []struct {
+		path     string
+		lang     string
+		expected ginstarter.StatusMessage
+	}{
+		{path: "/i18n/status", lang: "zh", expected: "请求成功"},
+		{path: "/i18n/status", lang: "en", expected: "Request Success"},
+		{path: "/i18n/forbidden", lang: "zh", expected: "请求被禁止"},
+		{path: "/i18n/forbidden?custom=1", lang: "zh", expected: "custom message"},
+	}
+	for _, c := range cases {
+		status := harness.Get(c.path).Query("lang", c.lang).Do().Rest().Status
+		if status.StatusMessage != c.expected {
+			t.Errorf("%s %s: expected %q actual %q", c.path, c.lang, c.expected, status.StatusMessage)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+	"github.com/golang-acexy/starter-gin/ginstarter/ginstartertest"
+	"github.com/golang-acexy/starter-gin/test/router"
+)
+
+func TestIPFilter(t *testing.T) {
+	global, err := ginstarter.NewIPFilter(&ginstarter.IPFilterConfig{
+		Deny: []string{"10.0.0.66"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		IPFilter: global,
+		Routers:  []ginstarter.Router{&router.IPFilterRouter{}},
+	})
+	// 未配置受信任代理时 伪造的X-Forwarded-For无效
+	harness.Get("/internal/ip").RemoteAddr("203.0.113.9:1234").Header("X-Forwarded-For", "127.0.0.1").Do().
+		AssertStatusCode(ginstarter.StatusCodeForbidden)
+	harness.Get("/internal/ip").RemoteAddr("10.0.0.1:1234").Do().AssertSuccess()
+	// Router白名单内的IP仍受全局黑名单限制
+	harness.Get("/internal/ip").RemoteAddr("10.0.0.66:1234").Do().
+		AssertStatusCode(ginstarter.StatusCodeForbidden)
+	harness.Get("/internal/ip").RemoteAddr("192.168.100.1:1234").Do().
+		AssertStatusCode(ginstarter.StatusCodeForbidden)
+}
+
+func TestIPFilterTrustedProxies(t *testing.T) {
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		TrustedProxies: []string{"10.1.0.0/16"},
+		Routers:        []ginstarter.Router{&router.IPFilterRouter{}},
+	})
+	// 来自受信任代理的请求使用转发的客户端IP
+	harness.Get("/internal/ip").RemoteAddr("10.1.0.2:1234").Header("X-Forwarded-For", "203.0.113.9").Do().
+		AssertStatusCode(ginstarter.StatusCodeForbidden)
+	harness.Get("/internal/ip").RemoteAddr("203.0.113.9:1234").Header("X-Forwarded-For", "127.0.0.1").Do().
+		AssertStatusCode(ginstarter.StatusCodeForbidden)
+	harness.Get("/internal/ip").RemoteAddr("10.1.0.2:1234").Header("X-Forwarded-For", "192.168.1.5").Do().
+		AssertSuccess()
+}
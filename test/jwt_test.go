@@ -0,0 +1,232 @@
+package test
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+	"github.com/golang-acexy/starter-gin/ginstarter/ginstartertest"
+	"github.com/golang-acexy/starter-gin/test/router"
+)
+
+// 签发测试token 支持HS256及RS256
+func signJwt(t *testing.T, header, claims map[string]any, key any) string {
+	t.Helper()
+	headerBytes, _ := json.Marshal(header)
+	claimsBytes, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(headerBytes) + "." + base64.RawURLEncoding.EncodeToString(claimsBytes)
+	var signature []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signingInput))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signingInput))
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:]); err != nil {
+			t.Fatal(err)
+		}
+	case nil:
+	default:
+		t.Fatalf("unsupported key %T", key)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func jwtHeader(alg, kid string) map[string]any {
+	header := map[string]any{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+	return header
+}
+
+func jwtExpiresIn(d time.Duration) map[string]any {
+	return map[string]any{"sub": "acexy", "iss": "acexy", "exp": time.Now().Add(d).Unix()}
+}
+
+// 使用公钥作为HS密钥或none算法伪造签名
+func TestVerifyJwtAlgorithmConfusion(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeyBytes, _ := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	config := &ginstarter.JwtConfig{KeySet: ginstarter.NewJwtKeySet(map[string]any{"rsa": &privateKey.PublicKey})}
+
+	if _, err = verifyRS256(t, config, privateKey); err != nil {
+		t.Fatalf("valid rs256 token rejected: %v", err)
+	}
+	cases := map[string]struct {
+		token    string
+		expected error
+	}{
+		"hs256 signed with public key": {signJwt(t, jwtHeader("HS256", "rsa"), jwtExpiresIn(time.Hour), publicKeyBytes), ginstarter.ErrJwtKeyNotFound},
+		"hs256 without kid":            {signJwt(t, jwtHeader("HS256", ""), jwtExpiresIn(time.Hour), publicKeyBytes), ginstarter.ErrJwtKeyNotFound},
+		"none":                         {signJwt(t, jwtHeader("none", "rsa"), jwtExpiresIn(time.Hour), nil), ginstarter.ErrJwtAlgorithm},
+		"NONE":                         {signJwt(t, jwtHeader("NONE", ""), jwtExpiresIn(time.Hour), nil), ginstarter.ErrJwtAlgorithm},
+	}
+	for name, c := range cases {
+		if _, err = ginstarter.VerifyJwt(c.token, config); !errors.Is(err, c.expected) {
+			t.Errorf("%s: expected %v actual %v", name, c.expected, err)
+		}
+	}
+	config.Algorithms = []string{"RS256"}
+	token := signJwt(t, jwtHeader("HS256", "rsa"), jwtExpiresIn(time.Hour), publicKeyBytes)
+	if _, err = ginstarter.VerifyJwt(token, config); !errors.Is(err, ginstarter.ErrJwtAlgorithm) {
+		t.Errorf("algorithm not in allow list: expected %v actual %v", ginstarter.ErrJwtAlgorithm, err)
+	}
+}
+
+func verifyRS256(t *testing.T, config *ginstarter.JwtConfig, privateKey *rsa.PrivateKey) (*ginstarter.JwtClaims, error) {
+	return ginstarter.VerifyJwt(signJwt(t, jwtHeader("RS256", "rsa"), jwtExpiresIn(time.Hour), privateKey), config)
+}
+
+func TestVerifyJwtExpiration(t *testing.T) {
+	secret := []byte("acexy")
+	config := &ginstarter.JwtConfig{KeySet: ginstarter.NewJwtSecretKeySet(secret), Leeway: 30 * time.Second}
+	cases := []struct {
+		name     string
+		claims   map[string]any
+		expected error
+	}{
+		{"valid", jwtExpiresIn(time.Hour), nil},
+		{"expired within leeway", jwtExpiresIn(-10 * time.Second), nil},
+		{"expired", jwtExpiresIn(-time.Minute), ginstarter.ErrJwtExpired},
+		{"not valid yet", map[string]any{"exp": time.Now().Add(time.Hour).Unix(), "nbf": time.Now().Add(time.Minute).Unix()}, ginstarter.ErrJwtNotValidYet},
+		{"no expiration", map[string]any{"sub": "acexy"}, nil},
+	}
+	for _, c := range cases {
+		if _, err := ginstarter.VerifyJwt(signJwt(t, jwtHeader("HS256", ""), c.claims, secret), config); !errors.Is(err, c.expected) {
+			t.Errorf("%s: expected %v actual %v", c.name, c.expected, err)
+		}
+	}
+	config.RequireExpiration = true
+	if _, err := ginstarter.VerifyJwt(signJwt(t, jwtHeader("HS256", ""), map[string]any{"sub": "acexy"}, secret), config); !errors.Is(err, ginstarter.ErrJwtNoExpiration) {
+		t.Errorf("require expiration: expected %v actual %v", ginstarter.ErrJwtNoExpiration, err)
+	}
+}
+
+func TestVerifyJwtKid(t *testing.T) {
+	keyA, keyB := []byte("secret-a"), []byte("secret-b")
+	config := &ginstarter.JwtConfig{KeySet: ginstarter.NewJwtKeySet(map[string]any{"a": keyA, "b": keyB})}
+	cases := []struct {
+		name     string
+		kid      string
+		key      []byte
+		expected error
+	}{
+		{"matched kid", "a", keyA, nil},
+		{"wrong key for kid", "b", keyA, ginstarter.ErrJwtSignature},
+		{"unknown kid", "c", keyA, ginstarter.ErrJwtKeyNotFound},
+		{"no kid with several keys", "", keyA, ginstarter.ErrJwtKeyNotFound},
+	}
+	for _, c := range cases {
+		// 多次验证 避免map遍历顺序导致的偶然通过
+		for i := 0; i < 10; i++ {
+			if _, err := ginstarter.VerifyJwt(signJwt(t, jwtHeader("HS256", c.kid), jwtExpiresIn(time.Hour), c.key), config); !errors.Is(err, c.expected) {
+				t.Errorf("%s: expected %v actual %v", c.name, c.expected, err)
+				break
+			}
+		}
+	}
+	single := &ginstarter.JwtConfig{KeySet: ginstarter.NewJwtSecretKeySet(keyA)}
+	if _, err := ginstarter.VerifyJwt(signJwt(t, jwtHeader("HS256", ""), jwtExpiresIn(time.Hour), keyA), single); err != nil {
+		t.Errorf("single key without kid: %v", err)
+	}
+	if _, err := ginstarter.VerifyJwt(signJwt(t, jwtHeader("HS256", "a"), jwtExpiresIn(time.Hour), keyA), single); !errors.Is(err, ginstarter.ErrJwtKeyNotFound) {
+		t.Errorf("single key with unknown kid: expected %v actual %v", ginstarter.ErrJwtKeyNotFound, err)
+	}
+}
+
+// 密钥轮换后遇到未知kid时重新获取JWKS 并限制刷新频率
+func TestVerifyJwtJwksRefresh(t *testing.T) {
+	var mutex sync.Mutex
+	var fetches atomic.Int32
+	keys := map[string][]byte{"k1": []byte("secret-k1")}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		mutex.Lock()
+		defer mutex.Unlock()
+		jwks := struct {
+			Keys []map[string]string `json:"keys"`
+		}{}
+		for kid, secret := range keys {
+			jwks.Keys = append(jwks.Keys, map[string]string{"kty": "oct", "kid": kid, "alg": "HS256", "k": base64.RawURLEncoding.EncodeToString(secret)})
+		}
+		_ = json.NewEncoder(w).Encode(jwks)
+	}))
+	defer server.Close()
+
+	minRefreshInterval := 50 * time.Millisecond
+	config := &ginstarter.JwtConfig{KeySet: ginstarter.NewJwksUrlKeySet(&ginstarter.JwksKeySetConfig{
+		Url:                server.URL,
+		MinRefreshInterval: minRefreshInterval,
+	})}
+	if _, err := ginstarter.VerifyJwt(signJwt(t, jwtHeader("HS256", "k1"), jwtExpiresIn(time.Hour), keys["k1"]), config); err != nil {
+		t.Fatalf("k1 rejected: %v", err)
+	}
+
+	mutex.Lock()
+	keys = map[string][]byte{"k2": []byte("secret-k2")}
+	mutex.Unlock()
+	token := signJwt(t, jwtHeader("HS256", "k2"), jwtExpiresIn(time.Hour), []byte("secret-k2"))
+	// 刷新间隔内不重新获取
+	if _, err := ginstarter.VerifyJwt(token, config); !errors.Is(err, ginstarter.ErrJwtKeyNotFound) {
+		t.Errorf("refresh within min interval: expected %v actual %v", ginstarter.ErrJwtKeyNotFound, err)
+	}
+	if fetches.Load() != 1 {
+		t.Errorf("expected 1 fetch actual %d", fetches.Load())
+	}
+	time.Sleep(minRefreshInterval * 2)
+	if _, err := ginstarter.VerifyJwt(token, config); err != nil {
+		t.Errorf("k2 rejected after refresh: %v", err)
+	}
+	if fetches.Load() != 2 {
+		t.Errorf("expected 2 fetches actual %d", fetches.Load())
+	}
+	// 已轮换的kid不再可用 未知kid不触发额外的获取
+	if _, err := ginstarter.VerifyJwt(signJwt(t, jwtHeader("HS256", "k1"), jwtExpiresIn(time.Hour), []byte("secret-k1")), config); !errors.Is(err, ginstarter.ErrJwtKeyNotFound) {
+		t.Errorf("rotated k1: expected %v actual %v", ginstarter.ErrJwtKeyNotFound, err)
+	}
+	if fetches.Load() != 2 {
+		t.Errorf("expected 2 fetches actual %d", fetches.Load())
+	}
+}
+
+// WWW-Authenticate中使用固定的错误描述
+func TestJwtInterceptorChallenge(t *testing.T) {
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		Routers: []ginstarter.Router{&router.JwtRouter{}},
+	})
+	harness.Get("/jwt/me").Do().
+		AssertStatusCode(ginstarter.StatusCodeUnauthorized).
+		AssertHeader("WWW-Authenticate", `Bearer realm="starter-gin"`)
+	expired := signJwt(t, jwtHeader("HS256", ""), jwtExpiresIn(-time.Hour), []byte("acexy"))
+	harness.Get("/jwt/me").BearerToken(expired).Do().
+		AssertStatusCode(ginstarter.StatusCodeUnauthorized).
+		AssertHeader("WWW-Authenticate", `Bearer realm="starter-gin", error="invalid_token", error_description="token expired"`)
+	forged := signJwt(t, jwtHeader("HS256", `x"`), jwtExpiresIn(time.Hour), []byte("acexy"))
+	harness.Get("/jwt/me").BearerToken(forged).Do().
+		AssertStatusCode(ginstarter.StatusCodeUnauthorized).
+		AssertHeader("WWW-Authenticate", `Bearer realm="starter-gin", error="invalid_token", error_description="token invalid"`)
+	valid := signJwt(t, jwtHeader("HS256", ""), jwtExpiresIn(time.Hour), []byte("acexy"))
+	if !strings.Contains(harness.Get("/jwt/me").BearerToken(valid).Do().AssertSuccess().String(), `"subject":"acexy"`) {
+		t.Error("valid token rejected")
+	}
+}
@@ -0,0 +1,96 @@
+package test
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+	"github.com/golang-acexy/starter-gin/ginstarter/ginstartertest"
+	"github.com/golang-acexy/starter-gin/test/router"
+)
+
+func assertMetricsLine(t *testing.T, scrape, line string) {
+	t.Helper()
+	for _, v := range strings.Split(scrape, "\n") {
+		if v == line {
+			return
+		}
+	}
+	t.Errorf("metrics line not found: %s", line)
+}
+
+func TestMetrics(t *testing.T) {
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		Metrics: &ginstarter.MetricsConfig{
+			SizeBuckets:           []float64{1000, 10, 100},
+			ExcludePaths:          []string{"/demo/common", "/metrics-demo/size/9*"},
+			DisableRuntimeMetrics: true,
+		},
+		Routers: []ginstarter.Router{&router.DemoRouter{}, &router.MetricsRouter{}},
+	})
+	for _, n := range []string{"5", "50", "500", "5000", "99"} {
+		harness.Get("/metrics-demo/size/" + n).Do().AssertHttpStatus(http.StatusOK)
+	}
+	// 未匹配路由使用unmatched标签 状态码分类使用BadHttpCodeResolver处理前的状态码
+	harness.Get("/not-found").Do().AssertHttpStatus(http.StatusOK).AssertStatusCode(ginstarter.StatusCodeNotFound)
+	harness.Get("/not-found/other").Do().AssertHttpStatus(http.StatusOK)
+	harness.Get("/demo/error2").Do().AssertHttpStatus(http.StatusOK).AssertStatusCode(ginstarter.StatusCodeException)
+	harness.Get("/demo/common").Do().AssertHttpStatus(http.StatusOK)
+
+	scrape := harness.Get("/metrics").Do().AssertHttpStatus(http.StatusOK).String()
+	assertMetricsLine(t, scrape, `http_server_requests_total{method="GET",route="unmatched",status="4xx"} 2`)
+	assertMetricsLine(t, scrape, `http_server_requests_total{method="GET",route="/demo/error2",status="5xx"} 1`)
+	assertMetricsLine(t, scrape, `http_server_panics_total{method="GET",route="/demo/error2",status="5xx"} 1`)
+	assertMetricsLine(t, scrape, `http_server_requests_total{method="GET",route="/metrics-demo/size/:n",status="2xx"} 4`)
+	// 分桶按上界排序并累计计数
+	labels := `method="GET",route="/metrics-demo/size/:n",status="2xx"`
+	for _, line := range []string{
+		`http_server_response_size_bytes_bucket{` + labels + `,le="10"} 1`,
+		`http_server_response_size_bytes_bucket{` + labels + `,le="100"} 2`,
+		`http_server_response_size_bytes_bucket{` + labels + `,le="1000"} 3`,
+		`http_server_response_size_bytes_bucket{` + labels + `,le="+Inf"} 4`,
+		`http_server_response_size_bytes_sum{` + labels + `} 5555`,
+		`http_server_response_size_bytes_count{` + labels + `} 4`,
+	} {
+		assertMetricsLine(t, scrape, line)
+	}
+	// 排除的路径及指标路径自身不统计
+	for _, excluded := range []string{`route="/demo/common"`, `route="/metrics"`, `route="/not-found`} {
+		if strings.Contains(scrape, excluded) {
+			t.Errorf("excluded series exported: %s", excluded)
+		}
+	}
+	if strings.Contains(scrape, "go_goroutines") {
+		t.Error("runtime metrics exported while disabled")
+	}
+}
+
+// 独立监听地址时指标不注册到业务服务
+func TestMetricsListenAddress(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	_ = listener.Close()
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		Metrics: &ginstarter.MetricsConfig{ListenAddress: address},
+		Routers: []ginstarter.Router{&router.MetricsRouter{}},
+	})
+	harness.Get("/metrics-demo/size/5").Do().AssertHttpStatus(http.StatusOK)
+	harness.Get("/metrics").Do().AssertStatusCode(ginstarter.StatusCodeNotFound)
+
+	response, err := http.Get("http://" + address + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+	scrape := string(body)
+	assertMetricsLine(t, scrape, `http_server_requests_total{method="GET",route="/metrics-demo/size/:n",status="2xx"} 1`)
+	// 业务服务上对指标路径的访问按未匹配路由统计
+	assertMetricsLine(t, scrape, `http_server_requests_total{method="GET",route="unmatched",status="4xx"} 1`)
+}
@@ -0,0 +1,59 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+	"github.com/golang-acexy/starter-gin/ginstarter/ginstartertest"
+	"github.com/golang-acexy/starter-gin/test/router"
+)
+
+// 超时控制下的panic保留handler的调用栈 内部中断请求的panic不上报
+func TestPanicReportWithTimeout(t *testing.T) {
+	reports := make(chan *ginstarter.PanicReport, 8)
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		RequestTimeout: 5 * time.Second,
+		PanicReport: &ginstarter.PanicReportConfig{
+			Reporter: ginstarter.PanicReporterFunc(func(report *ginstarter.PanicReport) {
+				reports <- report
+			}),
+		},
+		Routers: []ginstarter.Router{&router.DemoRouter{}, &router.AbortRouter{}},
+	})
+
+	harness.Get("/abort/panic").Do().AssertStatusCode(ginstarter.StatusCodeForbidden)
+	// 未配置受信任代理时 上报连接的远端地址而不是伪造的X-Forwarded-For
+	harness.Get("/demo/error2").RemoteAddr("203.0.113.9:1234").Header("X-Forwarded-For", "127.0.0.1").Do().
+		AssertStatusCode(ginstarter.StatusCodeException)
+	harness.Get("/demo/error3").RemoteAddr("203.0.113.9:1234").Do().AssertStatusCode(ginstarter.StatusCodeException)
+
+	fingerprints := make(map[string]string)
+	for _, handler := range []string{"error2", "error3"} {
+		select {
+		case report := <-reports:
+			if report.Route == "/abort/panic" {
+				t.Fatalf("internal panic reported: %v", report.Error)
+			}
+			expected := "(*DemoRouter)." + strings.TrimPrefix(report.Route, "/demo/")
+			if !strings.Contains(report.Stack, expected) {
+				t.Errorf("stack of %s does not contain handler %s:\n%s", report.Route, expected, report.Stack)
+			}
+			if report.ClientIP != "203.0.113.9" {
+				t.Errorf("unexpected client ip of %s: %s", report.Route, report.ClientIP)
+			}
+			fingerprints[report.Fingerprint] = report.Route
+		case <-time.After(5 * time.Second):
+			t.Fatalf("panic of %s not reported", handler)
+		}
+	}
+	if len(fingerprints) != 2 {
+		t.Errorf("expected distinct fingerprints actual %v", fingerprints)
+	}
+	select {
+	case report := <-reports:
+		t.Errorf("unexpected report %s %v", report.Route, report.Error)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
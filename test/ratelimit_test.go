@@ -0,0 +1,53 @@
+package test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+	"github.com/golang-acexy/starter-gin/ginstarter/ginstartertest"
+	"github.com/golang-acexy/starter-gin/test/router"
+)
+
+func TestRateLimit(t *testing.T) {
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		Routers: []ginstarter.Router{&router.RateLimitRouter{}},
+	})
+	harness.Get("/limit/bucket").RemoteAddr("203.0.113.9:1234").Do().
+		AssertHttpStatus(http.StatusOK).
+		AssertHeader("RateLimit-Limit", "2")
+	harness.Get("/limit/bucket").RemoteAddr("203.0.113.9:1234").Do().AssertHttpStatus(http.StatusOK)
+	// 未配置受信任代理时 伪造的X-Forwarded-For不能绕过限流
+	harness.Get("/limit/bucket").RemoteAddr("203.0.113.9:1234").Header("X-Forwarded-For", "198.51.100.1").Do().
+		AssertStatusCode(ginstarter.StatusCodeExceededLimit)
+	harness.Get("/limit/bucket").RemoteAddr("203.0.113.10:1234").Do().AssertHttpStatus(http.StatusOK)
+}
+
+func TestRateLimitTrustedProxies(t *testing.T) {
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		TrustedProxies: []string{"10.1.0.0/16"},
+		Routers:        []ginstarter.Router{&router.RateLimitRouter{}},
+	})
+	// 来自受信任代理的请求按转发的客户端IP限流
+	for range 2 {
+		harness.Get("/limit/bucket").RemoteAddr("10.1.0.2:1234").Header("X-Forwarded-For", "203.0.113.9").Do().
+			AssertHttpStatus(http.StatusOK)
+	}
+	harness.Get("/limit/bucket").RemoteAddr("10.1.0.2:1234").Header("X-Forwarded-For", "203.0.113.9").Do().
+		AssertStatusCode(ginstarter.StatusCodeExceededLimit)
+	harness.Get("/limit/bucket").RemoteAddr("10.1.0.2:1234").Header("X-Forwarded-For", "203.0.113.10").Do().
+		AssertHttpStatus(http.StatusOK)
+}
+
+func TestRateLimitInvalidLimit(t *testing.T) {
+	for _, limit := range []int{0, -1} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("rate limit interceptor with Limit %d should panic", limit)
+				}
+			}()
+			ginstarter.RateLimitInterceptor(&ginstarter.RateLimitConfig{Limit: limit})
+		}()
+	}
+}
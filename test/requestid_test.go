@@ -0,0 +1,88 @@
+package test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+	"github.com/golang-acexy/starter-gin/ginstarter/ginstartertest"
+	"github.com/golang-acexy/starter-gin/test/router"
+)
+
+var generatedRequestId = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+func TestRequestId(t *testing.T) {
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		RequestId: &ginstarter.RequestIdConfig{},
+		Routers:   []ginstarter.Router{&router.DemoRouter{}},
+	})
+	// 有效的请求ID原样回传
+	harness.Get("/demo/error4").Header("X-Request-Id", "order-svc:1a2b_3.c").Do().
+		AssertHeader("X-Request-Id", "order-svc:1a2b_3.c")
+	// 无效的请求ID被替换
+	for _, inbound := range []string{"bad id", "id\r\nSet-Cookie: a=b", "<script>", strings.Repeat("a", 129)} {
+		requestId := harness.Get("/demo/error4").Header("X-Request-Id", inbound).Do().Header().Get("X-Request-Id")
+		if !generatedRequestId.MatchString(requestId) {
+			t.Errorf("invalid inbound %q not replaced: %q", inbound, requestId)
+		}
+	}
+	// panic及BadHttpCodeResolver的响应同样携带请求ID
+	cases := []struct {
+		path   string
+		status ginstarter.StatusCode
+	}{
+		{"/demo/error2", ginstarter.StatusCodeException},
+		{"/not-found", ginstarter.StatusCodeNotFound},
+	}
+	for _, c := range cases {
+		harness.Get(c.path).Header("X-Request-Id", "r-1").Do().
+			AssertStatusCode(c.status).
+			AssertHeader("X-Request-Id", "r-1")
+		if requestId := harness.Get(c.path).Do().Header().Get("X-Request-Id"); !generatedRequestId.MatchString(requestId) {
+			t.Errorf("%s: request id not generated: %q", c.path, requestId)
+		}
+	}
+}
+
+func TestRequestIdConfig(t *testing.T) {
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		RequestId: &ginstarter.RequestIdConfig{
+			HeaderName:    "X-Correlation-Id",
+			IgnoreInbound: true,
+			Generator: func() string {
+				return "generated"
+			},
+		},
+		Routers: []ginstarter.Router{&router.DemoRouter{}},
+	})
+	harness.Get("/demo/error4").Header("X-Correlation-Id", "inbound").Do().
+		AssertHeader("X-Correlation-Id", "generated").
+		AssertHeader("X-Request-Id", "")
+}
+
+// 请求ID作为TraceId 用于Trace-Id响应头
+func TestRequestIdUseAsTraceId(t *testing.T) {
+	traceIdResponse := func() string {
+		return "trace"
+	}
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		TraceIdResponse: traceIdResponse,
+		RequestId:       &ginstarter.RequestIdConfig{UseAsTraceId: true},
+		Routers:         []ginstarter.Router{&router.DemoRouter{}},
+	})
+	harness.Get("/demo/error4").Header("X-Request-Id", "r-1").Do().
+		AssertHeader("X-Request-Id", "r-1").
+		AssertHeader("Trace-Id", "r-1")
+	harness.Get("/demo/error2").Header("X-Request-Id", "r-2").Do().
+		AssertHeader("Trace-Id", "r-2")
+
+	harness = ginstartertest.New(t, ginstarter.GinConfig{
+		TraceIdResponse: traceIdResponse,
+		RequestId:       &ginstarter.RequestIdConfig{},
+		Routers:         []ginstarter.Router{&router.DemoRouter{}},
+	})
+	harness.Get("/demo/error4").Header("X-Request-Id", "r-1").Do().
+		AssertHeader("X-Request-Id", "r-1").
+		AssertHeader("Trace-Id", "trace")
+}
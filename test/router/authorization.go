@@ -0,0 +1,76 @@
+package router
+
+import (
+	"strings"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+)
+
+type AuthorizationRouter struct {
+}
+
+// AuthorizationUser 携带角色及权限的认证主体
+type AuthorizationUser struct {
+	Name            string
+	UserRoles       []string
+	UserPermissions []string
+}
+
+func (u *AuthorizationUser) Subject() string {
+	return u.Name
+}
+
+func (u *AuthorizationUser) Roles() []string {
+	return u.UserRoles
+}
+
+func (u *AuthorizationUser) Permissions() []string {
+	return u.UserPermissions
+}
+
+func (a *AuthorizationRouter) Info() *ginstarter.RouterInfo {
+	return &ginstarter.RouterInfo{
+		GroupPath: "authz",
+
+		// 演示用 由请求头X-User X-Roles X-Permissions构造认证主体
+		PreInterceptors: []ginstarter.PreInterceptor{
+			func(request *ginstarter.Request) (ginstarter.Response, bool, bool) {
+				if name := request.GetHeader("X-User"); name != "" {
+					request.SetPrincipal(&AuthorizationUser{
+						Name:            name,
+						UserRoles:       splitHeader(request.GetHeader("X-Roles")),
+						UserPermissions: splitHeader(request.GetHeader("X-Permissions")),
+					})
+				}
+				return nil, true, true
+			},
+		},
+		// 该路由下所有接口都需要member角色
+		Authorization: ginstarter.RequireRoles("member"),
+	}
+}
+
+func (a *AuthorizationRouter) Handlers(router *ginstarter.RouterWrapper) {
+	// demo path /authz/me
+	router.GET("me", a.me())
+	// demo path /authz/any 具备admin或ops任意一个角色
+	router.WithAuthorization(ginstarter.RequireAnyRole("admin", "ops")).GET("any", a.me())
+	// demo path /authz/all 同时具备admin及ops角色
+	router.WithAuthorization(ginstarter.RequireRoles("admin", "ops")).GET("all", a.me())
+	// demo path /authz/project/1 具备对应项目的写权限
+	router.WithAuthorization(ginstarter.RequirePermissions("project:{id}:write")).PUT("project/:id", a.me())
+}
+
+func (a *AuthorizationRouter) me() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		principal, _ := request.Principal()
+		return ginstarter.RespRestSuccess(principal.Subject()), nil
+	}
+}
+
+func splitHeader(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
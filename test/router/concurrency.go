@@ -0,0 +1,39 @@
+package router
+
+import (
+	"github.com/golang-acexy/starter-gin/ginstarter"
+)
+
+type ConcurrencyRouter struct {
+	// handler开始执行时写入请求的id参数 携带hold参数时阻塞至Release关闭
+	Started chan string
+	Release chan struct{}
+}
+
+func (c *ConcurrencyRouter) Info() *ginstarter.RouterInfo {
+	return &ginstarter.RouterInfo{
+		GroupPath: "concurrency",
+	}
+}
+
+func (c *ConcurrencyRouter) Handlers(router *ginstarter.RouterWrapper) {
+	// demo path /concurrency/normal?id=1&hold
+	router.GET("normal", c.work())
+	// demo path /concurrency/high?id=1 过载时优先获取并发额度
+	router.WithPriority(ginstarter.RequestPriorityHigh).GET("high", c.work())
+	// demo path /concurrency/low?id=1 过载时优先被削减
+	router.WithPriority(ginstarter.RequestPriorityLow).GET("low", c.work())
+}
+
+func (c *ConcurrencyRouter) work() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		id, _ := request.GetQueryParam("id")
+		if c.Started != nil {
+			c.Started <- id
+		}
+		if _, hold := request.GetQueryParam("hold"); hold && c.Release != nil {
+			<-c.Release
+		}
+		return ginstarter.RespRestSuccess(id), nil
+	}
+}
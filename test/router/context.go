@@ -0,0 +1,56 @@
+package router
+
+import (
+	"context"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+)
+
+type ContextRouter struct {
+	// /context/wait开始执行时写入Started 请求上下文取消后将取消原因写入Cancelled
+	Started   chan struct{}
+	Cancelled chan error
+}
+
+func (c *ContextRouter) Info() *ginstarter.RouterInfo {
+	return &ginstarter.RouterInfo{
+		GroupPath: "context",
+	}
+}
+
+func (c *ContextRouter) Handlers(router *ginstarter.RouterWrapper) {
+	// demo path /context/value 通过ContextKey绑定数据 并从各类派生上下文中获取
+	router.GET("value", c.value())
+	// demo path /context/wait 等待请求上下文取消
+	router.GET("wait", c.wait())
+}
+
+func (c *ContextRouter) value() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		ContextKeyTenant.Set(request, "t1")
+		get, _ := ContextKeyTenant.Get(request)
+		derived, cancel := context.WithCancel(request.Context())
+		defer cancel()
+		value, _ := ContextKeyTenant.Value(derived)
+		// gin.Context作为context.Context使用时回退至Request.Context()
+		fallback, _ := ContextKeyTenant.Value(request.RawGinContext())
+		// 同名的不同键互不影响
+		other, found := ginstarter.NewContextKey[string]("tenant").Get(request)
+		return ginstarter.RespRestSuccess(map[string]any{
+			"get":      get,
+			"value":    value,
+			"fallback": fallback,
+			"other":    other,
+			"found":    found,
+		}), nil
+	}
+}
+
+func (c *ContextRouter) wait() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		c.Started <- struct{}{}
+		<-request.RawGinContext().Done()
+		c.Cancelled <- request.Context().Err()
+		return ginstarter.RespRestSuccess(), nil
+	}
+}
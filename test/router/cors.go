@@ -0,0 +1,38 @@
+package router
+
+import (
+	"time"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+)
+
+type CorsRouter struct {
+}
+
+func (c *CorsRouter) Info() *ginstarter.RouterInfo {
+	return &ginstarter.RouterInfo{
+		GroupPath: "cors",
+		// 覆盖全局跨域配置
+		Cors: &ginstarter.CorsConfig{
+			AllowOrigins:        []string{"https://*.acexy.cn", "http://localhost:*"},
+			AllowOriginPatterns: []string{`^https://app-\d+\.example\.com$`},
+			AllowMethods:        []string{"GET", "POST", "DELETE"},
+			AllowHeaders:        []string{"Content-Type", "Authorization"},
+			ExposeHeaders:       []string{"X-Total-Count"},
+			AllowCredentials:    true,
+			MaxAge:              time.Hour,
+		},
+	}
+}
+
+func (c *CorsRouter) Handlers(router *ginstarter.RouterWrapper) {
+	router.GET("items", c.items())
+	router.DELETE("items/:id", c.items())
+}
+
+func (c *CorsRouter) items() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		request.RawGinContext().Header("X-Total-Count", "2")
+		return ginstarter.RespRestSuccess([]string{"a", "b"}), nil
+	}
+}
@@ -9,6 +9,12 @@ import (
 	"github.com/golang-acexy/starter-gin/ginstarter"
 )
 
+// ErrOrderNotFound 预定义的业务错误
+var ErrOrderNotFound = ginstarter.RegisterBizError(10002, "order {0} not found")
+
+// ErrUserNotFound 注册时指定http状态码的业务错误
+var ErrUserNotFound = ginstarter.RegisterBizError(10003, "user {0} not found", ginstarter.BizErrorHttpStatusCode(http.StatusNotFound))
+
 type DemoRouter struct {
 }
 
@@ -26,9 +32,16 @@ func (d *DemoRouter) Handlers(router *ginstarter.RouterWrapper) {
 	router.GET("error1", d.error1())
 	router.GET("error2", d.error2())
 	router.GET("error3", d.error3())
+	// path /demo/error4 返回预定义的业务错误
+	router.GET("error4", d.error4())
+	// path /demo/error5 http状态码404的业务错误 仍响应业务错误结构
+	router.GET("error5", d.error5())
+	router.GET("error6", d.error6())
 
 	// path /demo/hold 5s的请求hold
 	router.GET("hold", d.hold())
+	// path /demo/hold-timeout 请求超过3s将被取消并响应超时
+	router.WithTimeout(time.Second*3).GET("hold-timeout", d.hold())
 
 	router.GET("empty", d.empty())
 
@@ -71,6 +84,26 @@ func (d *DemoRouter) error3() ginstarter.HandlerWrapper {
 	}
 }
 
+func (d *DemoRouter) error4() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		// 返回预定义的业务错误 响应标准Rest业务错误
+		return nil, ErrOrderNotFound.With("A001")
+	}
+}
+
+func (d *DemoRouter) error5() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		return nil, ErrUserNotFound.With("acexy")
+	}
+}
+
+func (d *DemoRouter) error6() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		// 仅本次响应使用409 不影响已注册的业务错误
+		return nil, ErrOrderNotFound.SetHttpStatusCode(http.StatusConflict).With("A002")
+	}
+}
+
 func (d *DemoRouter) hold() ginstarter.HandlerWrapper {
 	return func(request *ginstarter.Request) (ginstarter.Response, error) {
 		fmt.Println("invoke")
@@ -0,0 +1,57 @@
+package router
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+)
+
+// QuotaError 自定义错误类型 用于演示ErrorAsMapper
+type QuotaError struct {
+	Tenant string
+}
+
+func (e *QuotaError) Error() string {
+	return "quota exceeded tenant: " + e.Tenant
+}
+
+type ErrorMapperRouter struct {
+}
+
+func (e *ErrorMapperRouter) Info() *ginstarter.RouterInfo {
+	return &ginstarter.RouterInfo{
+		GroupPath: "mapper",
+	}
+}
+
+func (e *ErrorMapperRouter) Handlers(router *ginstarter.RouterWrapper) {
+	// demo path /mapper/no-rows 返回包装后的错误 由GinConfig.ErrorMappers转换为响应
+	router.GET(":kind", e.fail())
+}
+
+func (e *ErrorMapperRouter) fail() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		switch request.GetPathParam("kind") {
+		case "no-rows":
+			return nil, fmt.Errorf("query user: %w", sql.ErrNoRows)
+		case "deadline":
+			return nil, fmt.Errorf("call upstream: %w", context.DeadlineExceeded)
+		case "permission":
+			return nil, &fs.PathError{Op: "open", Path: "/secret", Err: fs.ErrPermission}
+		case "not-exist":
+			return nil, &fs.PathError{Op: "open", Path: "/missing", Err: fs.ErrNotExist}
+		case "joined":
+			return nil, errors.Join(fs.ErrNotExist, fs.ErrPermission)
+		case "quota":
+			return nil, fmt.Errorf("create order: %w", &QuotaError{Tenant: "t1"})
+		case "biz":
+			return nil, fmt.Errorf("load order: %w", ErrOrderNotFound.With("A001"))
+		default:
+			return nil, errors.New("unknown error")
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package router
+
+import (
+	"github.com/golang-acexy/starter-gin/ginstarter"
+)
+
+const BizErrorCodeOrderNotFound ginstarter.BizErrorCode = 10001
+
+type I18nRouter struct {
+}
+
+func (i *I18nRouter) Info() *ginstarter.RouterInfo {
+	return &ginstarter.RouterInfo{
+		GroupPath: "i18n",
+	}
+}
+
+func (i *I18nRouter) Handlers(router *ginstarter.RouterWrapper) {
+	// demo path /i18n/form?lang=zh    body > id=1
+	router.POST("form", i.form())
+	// demo path /i18n/biz/A001   header > Accept-Language: zh-CN,zh;q=0.9
+	router.GET("biz/:id", i.biz())
+	// demo path /i18n/order 预定义的业务错误未填充参数
+	router.GET("order", i.order())
+	// demo path /i18n/status?lang=zh 默认状态信息按请求语言本地化
+	router.GET("status", i.status())
+	router.GET("forbidden", i.forbidden())
+}
+
+func (i *I18nRouter) form() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		user := BodyFormUser{}
+		request.MustBindBodyForm(&user)
+		return ginstarter.RespRestSuccess(user), nil
+	}
+}
+
+func (i *I18nRouter) biz() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		return ginstarter.RespRestLocaleBizError(request, BizErrorCodeOrderNotFound, request.GetPathParam("id")), nil
+	}
+}
+
+func (i *I18nRouter) status() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		return ginstarter.RespRestSuccess(), nil
+	}
+}
+
+func (i *I18nRouter) forbidden() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		if _, ok := request.GetQueryParam("custom"); ok {
+			return ginstarter.RespRestStatusError(ginstarter.StatusCodeForbidden, "custom message"), nil
+		}
+		return ginstarter.RespRestStatusError(ginstarter.StatusCodeForbidden), nil
+	}
+}
+
+func (i *I18nRouter) order() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		return nil, ErrOrderNotFound
+	}
+}
@@ -0,0 +1,33 @@
+package router
+
+import (
+	"github.com/golang-acexy/starter-gin/ginstarter"
+)
+
+type IPFilterRouter struct {
+}
+
+func (i *IPFilterRouter) Info() *ginstarter.RouterInfo {
+	filter, err := ginstarter.NewIPFilter(&ginstarter.IPFilterConfig{
+		// 仅允许内网访问
+		Allow: []string{"127.0.0.1", "::1", "10.0.0.0/8", "192.168.0.0/16"},
+		Deny:  []string{"192.168.100.0/24"},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return &ginstarter.RouterInfo{
+		GroupPath: "internal",
+		IPFilter:  filter,
+	}
+}
+
+func (i *IPFilterRouter) Handlers(router *ginstarter.RouterWrapper) {
+	router.GET("ip", i.ip())
+}
+
+func (i *IPFilterRouter) ip() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		return ginstarter.RespRestSuccess(request.RequestIP()), nil
+	}
+}
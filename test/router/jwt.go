@@ -0,0 +1,60 @@
+package router
+
+import (
+	"time"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+)
+
+type JwtRouter struct {
+}
+
+type JwtUser struct {
+	*ginstarter.JwtClaims
+	Role string
+}
+
+func (j *JwtRouter) Info() *ginstarter.RouterInfo {
+	return &ginstarter.RouterInfo{
+		GroupPath: "jwt",
+
+		PreInterceptors: []ginstarter.PreInterceptor{
+			ginstarter.JwtInterceptor(&ginstarter.JwtConfig{
+				KeySet:         ginstarter.NewJwtSecretKeySet([]byte("acexy")),
+				Algorithms:     []string{"HS256"},
+				Issuer:         "acexy",
+				Leeway:         time.Second * 30,
+				CookieName:     "token",
+				QueryParamName: "token",
+				Realm:          "starter-gin",
+				// 将claims转换为自定义认证主体
+				PrincipalFunc: func(claims *ginstarter.JwtClaims) (ginstarter.Principal, error) {
+					var custom struct {
+						Role string `json:"role"`
+					}
+					if err := claims.Decode(&custom); err != nil {
+						return nil, err
+					}
+					return &JwtUser{JwtClaims: claims, Role: custom.Role}, nil
+				},
+			}),
+		},
+	}
+}
+
+func (j *JwtRouter) Handlers(router *ginstarter.RouterWrapper) {
+	router.GET("me", j.me())
+	// 声明访问授权要求 角色及权限取自token的roles/permissions声明
+	router.WithAuthorization(ginstarter.RequireAnyRole("admin", "ops")).GET("admin", j.me())
+	router.WithAuthorization(ginstarter.RequirePermissions("project:{id}:write")).PUT("project/:id", j.me())
+}
+
+func (j *JwtRouter) me() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		user, _ := ginstarter.PrincipalAs[*JwtUser](request)
+		return ginstarter.RespRestSuccess(map[string]string{
+			"subject": user.Subject(),
+			"role":    user.Role,
+		}), nil
+	}
+}
@@ -0,0 +1,29 @@
+package router
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+)
+
+type MetricsRouter struct {
+}
+
+func (m *MetricsRouter) Info() *ginstarter.RouterInfo {
+	return &ginstarter.RouterInfo{
+		GroupPath: "metrics-demo",
+	}
+}
+
+func (m *MetricsRouter) Handlers(router *ginstarter.RouterWrapper) {
+	// demo path /metrics-demo/size/100 响应指定字节数的内容
+	router.GET("size/:n", m.size())
+}
+
+func (m *MetricsRouter) size() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		n, _ := strconv.Atoi(request.GetPathParam("n"))
+		return ginstarter.RespTextPlain(bytes.Repeat([]byte("a"), n)), nil
+	}
+}
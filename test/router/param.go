@@ -63,6 +63,10 @@ type BodyFormUser struct {
 	Name   string `form:"name" binding:"required"`
 	Email  string `form:"email" binding:"required,email"`
 	Domain string `form:"domain" binding:"domain"`
+	Mobile string `form:"mobile" binding:"omitempty,mobile"`
+	// json忽略时验证错误信息使用form声明的名称
+	NickName string `json:"-" form:"nick_name" binding:"max=8"`
+	HomePage string `form:"home_page" binding:"omitempty,url"`
 }
 
 func (d *ParamRouter) path() ginstarter.HandlerWrapper {
@@ -0,0 +1,49 @@
+package router
+
+import (
+	"time"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+)
+
+type RateLimitRouter struct {
+}
+
+func (r *RateLimitRouter) Info() *ginstarter.RouterInfo {
+	return &ginstarter.RouterInfo{
+		GroupPath: "limit",
+
+		PreInterceptors: []ginstarter.PreInterceptor{
+			// 每个客户端IP每秒最多2个请求
+			ginstarter.RateLimitInterceptor(&ginstarter.RateLimitConfig{
+				Limit:  2,
+				Window: time.Second,
+			}),
+			// 滑动窗口 该路由所有请求每分钟最多10个
+			ginstarter.RateLimitInterceptor(&ginstarter.RateLimitConfig{
+				Algorithm: ginstarter.RateLimitSlidingWindow,
+				Limit:     10,
+				Window:    time.Minute,
+				KeyFunc:   ginstarter.RateLimitKeyByRoute(),
+			}, func(request *ginstarter.Request) bool {
+				return request.RouterFullPath() == "/limit/window"
+			}),
+		},
+	}
+}
+
+func (r *RateLimitRouter) Handlers(router *ginstarter.RouterWrapper) {
+	router.GET("bucket", r.invoke())
+	router.GET("window", r.invoke())
+	// 单个路由最多2个并发请求 超出后最多排队等待1秒
+	router.WithConcurrencyLimit(&ginstarter.ConcurrencyLimitConfig{
+		MaxConcurrency: 2,
+		MaxWait:        time.Second,
+	}).WithPriority(ginstarter.RequestPriorityLow).GET("concurrency", r.invoke())
+}
+
+func (r *RateLimitRouter) invoke() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		return ginstarter.RespTextPlain([]byte("request not limited")), nil
+	}
+}
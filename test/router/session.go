@@ -0,0 +1,76 @@
+package router
+
+import (
+	"github.com/golang-acexy/starter-gin/ginstarter"
+)
+
+type SessionRouter struct {
+}
+
+type SessionUser struct {
+	Username string `json:"username"`
+}
+
+func (s *SessionRouter) Info() *ginstarter.RouterInfo {
+	return &ginstarter.RouterInfo{
+		GroupPath: "session",
+
+		// 同步令牌模式 token保存在会话中 安全方法的响应头X-CSRF-Token中返回token
+		Csrf: &ginstarter.CsrfConfig{
+			Mode: ginstarter.CsrfSynchronizerToken,
+		},
+	}
+}
+
+func (s *SessionRouter) Handlers(router *ginstarter.RouterWrapper) {
+	router.GET("login", s.login())
+	router.GET("me", s.me())
+	router.GET("logout", s.logout())
+	router.GET("csrf", s.csrf())
+	router.POST("profile", s.profile())
+}
+
+func (s *SessionRouter) login() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		session := request.Session()
+		// 登录后更换会话ID 防止会话固定攻击 并下发新的CSRF token
+		session.Rotate()
+		request.CsrfToken()
+		session.Set("user", SessionUser{Username: request.MustGetQueryParam("username")})
+		session.AddFlash("welcome")
+		return ginstarter.RespRestSuccess(), nil
+	}
+}
+
+func (s *SessionRouter) me() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		user, ok := ginstarter.SessionValue[SessionUser](request, "user")
+		if !ok {
+			return ginstarter.RespRestUnAuthorized(), nil
+		}
+		return ginstarter.RespRestSuccess(map[string]any{
+			"user":    user,
+			"flashes": request.Session().Flashes(),
+		}), nil
+	}
+}
+
+func (s *SessionRouter) logout() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		request.Session().Destroy()
+		return ginstarter.RespRestSuccess(), nil
+	}
+}
+
+func (s *SessionRouter) csrf() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		return ginstarter.RespRestSuccess(map[string]string{"token": request.CsrfToken()}), nil
+	}
+}
+
+func (s *SessionRouter) profile() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		request.Session().Set("nickname", request.MustGetFormValue("nickname"))
+		return ginstarter.RespRestSuccess(), nil
+	}
+}
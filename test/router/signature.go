@@ -0,0 +1,62 @@
+package router
+
+import (
+	"github.com/golang-acexy/starter-gin/ginstarter"
+)
+
+type SignatureRouter struct {
+}
+
+// 签名密钥 签名请求明文携带密钥标识 不可作为API Key使用
+var partnerSigningKeys = ginstarter.NewApiKeyStore(map[string]string{
+	"partner": "partner-secret",
+})
+
+// API Key 请求头 X-Api-Key: reader.reader-api-key
+var partnerApiKeys = ginstarter.NewApiKeyStore(map[string]string{
+	"reader": "reader-api-key",
+})
+
+func (s *SignatureRouter) Info() *ginstarter.RouterInfo {
+	return &ginstarter.RouterInfo{
+		GroupPath: "partner",
+
+		PreInterceptors: []ginstarter.PreInterceptor{
+			// 签名认证 X-Api-Key X-Timestamp X-Nonce X-Signature
+			ginstarter.SignatureInterceptor(&ginstarter.SignatureConfig{
+				Store: partnerSigningKeys,
+				// 请求body最大1KB
+				MaxBodySize: 1 << 10,
+			}, func(request *ginstarter.Request) bool {
+				return request.RouterFullPath() == "/partner/notify"
+			}),
+			// 仅校验API Key
+			ginstarter.ApiKeyInterceptor(&ginstarter.ApiKeyConfig{
+				Store: partnerApiKeys,
+			}, func(request *ginstarter.Request) bool {
+				return request.RouterFullPath() == "/partner/query"
+			}),
+		},
+	}
+}
+
+func (s *SignatureRouter) Handlers(router *ginstarter.RouterWrapper) {
+	router.POST("notify", s.notify())
+	router.GET("query", s.query())
+}
+
+func (s *SignatureRouter) notify() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		// 签名校验已读取body 仍可正常绑定
+		var body map[string]any
+		request.MustBindBodyJson(&body)
+		return ginstarter.RespRestSuccess(body), nil
+	}
+}
+
+func (s *SignatureRouter) query() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		principal, _ := request.Principal()
+		return ginstarter.RespRestSuccess(principal.Subject()), nil
+	}
+}
@@ -0,0 +1,69 @@
+package router
+
+import (
+	"time"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+)
+
+// ContextKeyTenant 请求上下文中的租户
+var ContextKeyTenant = ginstarter.NewContextKey[string]("tenant")
+
+type TimeoutRouter struct {
+	// 阻塞的handler开始执行时写入Started 直到Release关闭后返回 返回后写入Finished 均为nil时立即返回
+	Started  chan struct{}
+	Release  chan struct{}
+	Finished chan struct{}
+}
+
+type TimeoutUser struct {
+	Name string
+}
+
+func (u *TimeoutUser) Subject() string {
+	return u.Name
+}
+
+func (t *TimeoutRouter) Info() *ginstarter.RouterInfo {
+	return &ginstarter.RouterInfo{
+		GroupPath: "timeout",
+	}
+}
+
+func (t *TimeoutRouter) Handlers(router *ginstarter.RouterWrapper) {
+	// demo path /timeout/principal 超时控制下设置的认证主体及上下文数据对后续处理可见
+	router.WithTimeout(time.Second).GET("principal", t.principal())
+	// demo path /timeout/slow 忽略取消信号的handler 结束前持续占用并发额度
+	router.WithTimeout(time.Millisecond*50).WithConcurrencyLimit(&ginstarter.ConcurrencyLimitConfig{
+		MaxConcurrency: 1,
+	}).GET("slow", t.block())
+	// demo path /timeout/hold 超时时间足够长 用于观察客户端断开
+	router.WithTimeout(time.Minute).WithConcurrencyLimit(&ginstarter.ConcurrencyLimitConfig{
+		MaxConcurrency: 1,
+	}).GET("hold", t.block())
+	// demo path /timeout/inherit 0表示继承RouterInfo及全局配置的超时时间
+	router.WithTimeout(0).GET("inherit", t.block())
+}
+
+func (t *TimeoutRouter) principal() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		request.SetPrincipal(&TimeoutUser{Name: "acexy"})
+		ContextKeyTenant.Set(request, "t1")
+		return ginstarter.RespRestSuccess(), nil
+	}
+}
+
+func (t *TimeoutRouter) block() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		if t.Finished != nil {
+			defer func() { t.Finished <- struct{}{} }()
+		}
+		if t.Started != nil {
+			t.Started <- struct{}{}
+		}
+		if t.Release != nil {
+			<-t.Release
+		}
+		return ginstarter.RespRestSuccess(), nil
+	}
+}
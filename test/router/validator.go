@@ -0,0 +1,100 @@
+package router
+
+import (
+	"reflect"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-acexy/starter-gin/ginstarter"
+)
+
+// Money 金额 通过自定义类型取值以分参与验证
+type Money struct {
+	Cents int64 `json:"cents"`
+}
+
+// QuantityRange 数量范围 通过结构体级别验证校验max不小于min
+type QuantityRange struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+type ValidatorOrder struct {
+	Quantity int           `json:"quantity" binding:"even"`
+	Sku      string        `json:"sku" binding:"sku"`
+	Price    Money         `json:"price" binding:"gt=0"`
+	Range    QuantityRange `json:"range"`
+}
+
+// OrderValidatorConfig 注册ValidatorOrder使用的自定义验证
+func OrderValidatorConfig() *ginstarter.ValidatorConfig {
+	return &ginstarter.ValidatorConfig{
+		Tags: []*ginstarter.ValidatorTag{
+			{
+				Tag: "even",
+				Func: func(fl validator.FieldLevel) bool {
+					return fl.Field().Int()%2 == 0
+				},
+				Message:  "{0} must be even",
+				Messages: map[string]string{ginstarter.LocaleZh: "{0}必须是偶数"},
+			},
+			// 仅注册错误信息 由结构体级别验证上报
+			{
+				Tag:      "gtemin",
+				Message:  "{0} must not be less than min",
+				Messages: map[string]string{ginstarter.LocaleZh: "{0}不能小于最小值"},
+			},
+		},
+		Aliases: []*ginstarter.ValidatorAlias{
+			{
+				Alias:    "sku",
+				Tags:     "alphanum,len=8",
+				Message:  "{0} must be an 8 character sku",
+				Messages: map[string]string{ginstarter.LocaleZh: "{0}必须是8位的商品编码"},
+			},
+		},
+		StructLevels: []*ginstarter.ValidatorStructLevel{
+			{
+				Func: func(sl validator.StructLevel) {
+					quantityRange := sl.Current().Interface().(QuantityRange)
+					if quantityRange.Max < quantityRange.Min {
+						sl.ReportError(quantityRange.Max, "max", "Max", "gtemin", "")
+					}
+				},
+				Types: []any{QuantityRange{}},
+			},
+		},
+		CustomTypes: []*ginstarter.ValidatorCustomType{
+			{
+				Func: func(field reflect.Value) any {
+					if money, ok := field.Interface().(Money); ok {
+						return money.Cents
+					}
+					return nil
+				},
+				Types: []any{Money{}},
+			},
+		},
+	}
+}
+
+type ValidatorRouter struct {
+}
+
+func (v *ValidatorRouter) Info() *ginstarter.RouterInfo {
+	return &ginstarter.RouterInfo{
+		GroupPath: "validator",
+	}
+}
+
+func (v *ValidatorRouter) Handlers(router *ginstarter.RouterWrapper) {
+	// demo path /validator/order  body > {"quantity":2,"sku":"ABCD1234","price":{"cents":100},"range":{"min":1,"max":5}}
+	router.POST("order", v.order())
+}
+
+func (v *ValidatorRouter) order() ginstarter.HandlerWrapper {
+	return func(request *ginstarter.Request) (ginstarter.Response, error) {
+		order := ValidatorOrder{}
+		request.MustBindBodyJson(&order)
+		return ginstarter.RespRestSuccess(), nil
+	}
+}
@@ -0,0 +1,132 @@
+package test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+	"github.com/golang-acexy/starter-gin/ginstarter/ginstartertest"
+	"github.com/golang-acexy/starter-gin/test/router"
+)
+
+func sessionCookie(t *testing.T, response *ginstartertest.Response) *http.Cookie {
+	t.Helper()
+	for _, cookie := range response.Cookies() {
+		if cookie.Name == "session" {
+			return cookie
+		}
+	}
+	t.Fatal("session cookie not issued")
+	return nil
+}
+
+// 使用指定的Cookie访问需要登录的接口
+func sessionMe(harness *ginstartertest.Harness, cookie *http.Cookie) *ginstartertest.Response {
+	harness.ClearCookies()
+	return harness.Get("/session/me").Cookie(cookie).Do()
+}
+
+// 更换会话ID后旧ID及旧CSRF token失效
+func TestSessionRotate(t *testing.T) {
+	store := ginstarter.NewMemorySessionStore()
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		SessionConfig: &ginstarter.SessionConfig{Store: store, SecretKey: []byte("acexy")},
+		Routers:       []ginstarter.Router{&router.SessionRouter{}},
+	})
+	oldCookie := sessionCookie(t, harness.Get("/session/login").Query("username", "acexy").Do().AssertSuccess())
+	oldToken := harness.Get("/session/me").Do().AssertSuccess().Header().Get("X-CSRF-Token")
+
+	response := harness.Get("/session/login").Query("username", "acexy").Do().AssertSuccess()
+	newCookie := sessionCookie(t, response)
+	newToken := response.Header().Get("X-CSRF-Token")
+	if newCookie.Value == oldCookie.Value || newToken == "" || newToken == oldToken {
+		t.Fatalf("session not rotated cookie: %s token: %s", newCookie.Value, newToken)
+	}
+	oldId, _, _ := strings.Cut(oldCookie.Value, ".")
+	if data, _ := store.Load(context.Background(), oldId); data != nil {
+		t.Error("rotated session id still in store")
+	}
+	sessionMe(harness, oldCookie).AssertStatusCode(ginstarter.StatusCodeUnauthorized)
+
+	harness.ClearCookies()
+	harness.Post("/session/profile").Cookie(newCookie).Header("X-CSRF-Token", oldToken).
+		Form(map[string]string{"nickname": "acexy"}).Do().
+		AssertStatusCode(ginstarter.StatusCodeForbidden)
+	harness.Post("/session/profile").Cookie(newCookie).Header("X-CSRF-Token", newToken).
+		Form(map[string]string{"nickname": "acexy"}).Do().
+		AssertSuccess()
+}
+
+// 篡改Cookie存储的会话数据后会话失效
+func TestSessionCookieTampering(t *testing.T) {
+	configs := map[string]*ginstarter.SessionConfig{
+		"signed":    {SecretKey: []byte("acexy")},
+		"encrypted": {EncryptionKey: []byte("0123456789abcdef")},
+	}
+	for name, config := range configs {
+		harness := ginstartertest.New(t, ginstarter.GinConfig{
+			SessionConfig: config,
+			Routers:       []ginstarter.Router{&router.SessionRouter{}},
+		})
+		cookie := sessionCookie(t, harness.Get("/session/login").Query("username", "acexy").Do().AssertSuccess())
+		if name == "encrypted" && strings.Contains(cookie.Value, ".") {
+			t.Errorf("encrypted session cookie is signed plaintext: %s", cookie.Value)
+		}
+		sessionMe(harness, cookie).AssertSuccess()
+		for _, i := range []int{0, len(cookie.Value) / 2, len(cookie.Value) - 2} {
+			tampered := []byte(cookie.Value)
+			if tampered[i] == 'A' {
+				tampered[i] = 'B'
+			} else {
+				tampered[i] = 'A'
+			}
+			sessionMe(harness, &http.Cookie{Name: "session", Value: string(tampered)}).
+				AssertStatusCode(ginstarter.StatusCodeUnauthorized)
+		}
+	}
+}
+
+// 签发指定时间的签名会话Cookie
+func signedSessionCookie(t *testing.T, secretKey []byte, createdAt, lastAccess time.Time) *http.Cookie {
+	t.Helper()
+	payload, err := json.Marshal(map[string]any{
+		"i": "forged",
+		"v": map[string]any{"user": map[string]any{"username": "acexy"}},
+		"c": createdAt.Unix(),
+		"a": lastAccess.Unix(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, secretKey)
+	mac.Write([]byte("session|" + value))
+	return &http.Cookie{Name: "session", Value: value + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))}
+}
+
+func TestSessionExpiration(t *testing.T) {
+	secretKey := []byte("acexy")
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		SessionConfig: &ginstarter.SessionConfig{
+			SecretKey:       secretKey,
+			IdleTimeout:     30 * time.Minute,
+			AbsoluteTimeout: 24 * time.Hour,
+		},
+		Routers: []ginstarter.Router{&router.SessionRouter{}},
+	})
+	now := time.Now()
+	sessionMe(harness, signedSessionCookie(t, secretKey, now.Add(-time.Hour), now.Add(-time.Minute))).AssertSuccess()
+	// 超过空闲过期时间未访问
+	sessionMe(harness, signedSessionCookie(t, secretKey, now.Add(-time.Hour), now.Add(-31*time.Minute))).
+		AssertStatusCode(ginstarter.StatusCodeUnauthorized)
+	// 持续访问但超过绝对过期时间
+	sessionMe(harness, signedSessionCookie(t, secretKey, now.Add(-25*time.Hour), now.Add(-time.Minute))).
+		AssertStatusCode(ginstarter.StatusCodeUnauthorized)
+}
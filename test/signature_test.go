@@ -0,0 +1,94 @@
+package test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+	"github.com/golang-acexy/starter-gin/ginstarter/ginstartertest"
+	"github.com/golang-acexy/starter-gin/test/router"
+)
+
+func TestApiKey(t *testing.T) {
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		Routers: []ginstarter.Router{&router.SignatureRouter{}},
+	})
+	for _, apiKey := range []string{"", "reader", "reader.", "reader.wrong", "partner", "partner.partner-secret", ".reader-api-key"} {
+		harness.Get("/partner/query").Header("X-Api-Key", apiKey).Do().
+			AssertStatusCode(ginstarter.StatusCodeUnauthorized)
+	}
+	harness.Get("/partner/query").Header("X-Api-Key", "reader.reader-api-key").Do().
+		AssertHttpStatus(http.StatusOK).
+		AssertSuccess().
+		AssertData(`"reader"`)
+}
+
+// 按默认待签名字符串签名
+func signPartnerRequest(method, path, query, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte("partner-secret"))
+	mac.Write([]byte(strings.Join([]string{method, path, query, timestamp, nonce, hex.EncodeToString(bodyHash[:])}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestSignature(t *testing.T) {
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		Routers: []ginstarter.Router{&router.SignatureRouter{}},
+	})
+	body := []byte(`{"order":"A001"}`)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	notify := func(url, timestamp, nonce, signature string, body []byte) *ginstartertest.Response {
+		return harness.Post(url).
+			Header("X-Api-Key", "partner").
+			Header("X-Timestamp", timestamp).
+			Header("X-Nonce", nonce).
+			Header("X-Signature", signature).
+			Body("application/json", body).Do()
+	}
+
+	// 查询参数顺序不影响签名 签名校验后处理器仍可读取body
+	signature := signPartnerRequest(http.MethodPost, "/partner/notify", "a=1&b=2&b=3", now, "n1", body)
+	notify("/partner/notify?b=3&a=1&b=2", now, "n1", signature, body).
+		AssertSuccess().
+		AssertData(`{"order":"A001"}`)
+	// nonce重放
+	notify("/partner/notify?b=3&a=1&b=2", now, "n1", signature, body).
+		AssertStatusCode(ginstarter.StatusCodeUnauthorized)
+
+	// 篡改body
+	signature = signPartnerRequest(http.MethodPost, "/partner/notify", "", now, "n2", body)
+	notify("/partner/notify", now, "n2", signature, []byte(`{"order":"A002"}`)).
+		AssertStatusCode(ginstarter.StatusCodeUnauthorized)
+	// 篡改查询参数
+	signature = signPartnerRequest(http.MethodPost, "/partner/notify", "a=1", now, "n3", body)
+	notify("/partner/notify?a=2", now, "n3", signature, body).
+		AssertStatusCode(ginstarter.StatusCodeUnauthorized)
+	notify("/partner/notify?a=1&admin=true", now, "n3", signature, body).
+		AssertStatusCode(ginstarter.StatusCodeUnauthorized)
+	// 篡改路径
+	signature = signPartnerRequest(http.MethodPost, "/partner/other", "", now, "n4", body)
+	notify("/partner/notify", now, "n4", signature, body).
+		AssertStatusCode(ginstarter.StatusCodeUnauthorized)
+
+	// 超出允许的时钟偏差
+	expired := strconv.FormatInt(time.Now().Add(-6*time.Minute).Unix(), 10)
+	signature = signPartnerRequest(http.MethodPost, "/partner/notify", "", expired, "n5", body)
+	notify("/partner/notify", expired, "n5", signature, body).
+		AssertStatusCode(ginstarter.StatusCodeUnauthorized)
+	future := strconv.FormatInt(time.Now().Add(6*time.Minute).Unix(), 10)
+	signature = signPartnerRequest(http.MethodPost, "/partner/notify", "", future, "n6", body)
+	notify("/partner/notify", future, "n6", signature, body).
+		AssertStatusCode(ginstarter.StatusCodeUnauthorized)
+
+	// 超出MaxBodySize时不缓存body
+	large := []byte(`{"order":"` + strings.Repeat("A", 2048) + `"}`)
+	signature = signPartnerRequest(http.MethodPost, "/partner/notify", "", now, "n7", large)
+	notify("/partner/notify", now, "n7", signature, large).
+		AssertStatusCode(ginstarter.StatusCodeUploadLimitExceeded)
+}
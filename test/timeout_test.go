@@ -0,0 +1,119 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-acexy/starter-gin/ginstarter"
+	"github.com/golang-acexy/starter-gin/ginstarter/ginstartertest"
+	"github.com/golang-acexy/starter-gin/test/router"
+)
+
+// 超时控制下handler对请求上下文的修改对后置拦截器可见
+func TestTimeoutContextPropagation(t *testing.T) {
+	var subject, tenant string
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		Routers: []ginstarter.Router{&router.TimeoutRouter{}},
+		GlobalPostInterceptors: []ginstarter.PostInterceptor{
+			func(request *ginstarter.Request, response ginstarter.Response) (ginstarter.Response, bool) {
+				if principal, ok := request.Principal(); ok {
+					subject = principal.Subject()
+				}
+				tenant, _ = router.ContextKeyTenant.Get(request)
+				return response, true
+			},
+		},
+	})
+	harness.Get("/timeout/principal").Do().AssertSuccess()
+	if subject != "acexy" || tenant != "t1" {
+		t.Errorf("context lost after timeout handler subject: %q tenant: %q", subject, tenant)
+	}
+}
+
+func newBlockingTimeoutRouter() *router.TimeoutRouter {
+	return &router.TimeoutRouter{
+		Started:  make(chan struct{}, 8),
+		Release:  make(chan struct{}),
+		Finished: make(chan struct{}, 8),
+	}
+}
+
+// 等待被放弃的handler释放并发额度 释放由独立协程异步完成 仅以宽松的截止时间兜底
+func awaitTimeoutSlot(t *testing.T, harness *ginstartertest.Harness, path string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		response := harness.Get(path).Do()
+		if response.Rest().IsSuccess() {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("concurrency slot of %s not released: %s", path, response.String())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// 客户端断开时不响应超时
+func TestTimeoutClientDisconnect(t *testing.T) {
+	timeoutRouter := newBlockingTimeoutRouter()
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		Routers: []ginstarter.Router{timeoutRouter},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	request := httptest.NewRequest(http.MethodGet, "/timeout/hold", nil).WithContext(ctx)
+	recorder := httptest.NewRecorder()
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		harness.Engine().ServeHTTP(recorder, request)
+	}()
+	<-timeoutRouter.Started
+	cancel()
+	<-served
+	if recorder.Code == http.StatusGatewayTimeout || recorder.Body.Len() > 0 {
+		t.Errorf("response written for disconnected client status: %d body: %s", recorder.Code, recorder.Body.String())
+	}
+	// 被放弃的handler结束前持续占用并发额度
+	harness.Get("/timeout/hold").Do().AssertStatusCode(ginstarter.StatusCodeServiceUnavailable)
+	close(timeoutRouter.Release)
+	awaitTimeoutSlot(t, harness, "/timeout/hold")
+}
+
+// 超时后handler仍在执行时继续占用并发额度
+func TestTimeoutHoldsConcurrency(t *testing.T) {
+	timeoutRouter := newBlockingTimeoutRouter()
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		Routers: []ginstarter.Router{timeoutRouter},
+	})
+	harness.Get("/timeout/slow").Do().AssertStatusCode(ginstarter.StatusCodeTimeout)
+	<-timeoutRouter.Started
+	harness.Get("/timeout/slow").Do().AssertStatusCode(ginstarter.StatusCodeServiceUnavailable)
+	close(timeoutRouter.Release)
+	awaitTimeoutSlot(t, harness, "/timeout/slow")
+}
+
+// WithTimeout(0)继承全局配置的超时时间
+func TestTimeoutInherit(t *testing.T) {
+	timeoutRouter := newBlockingTimeoutRouter()
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		RequestTimeout: time.Millisecond * 50,
+		Routers:        []ginstarter.Router{timeoutRouter},
+	})
+	done := make(chan *ginstartertest.Response, 1)
+	go func() {
+		done <- harness.Get("/timeout/inherit").Do()
+	}()
+	select {
+	case response := <-done:
+		response.AssertStatusCode(ginstarter.StatusCodeTimeout)
+	case <-time.After(5 * time.Second):
+		t.Error("route registered with WithTimeout(0) did not inherit the request timeout")
+	}
+	// 等待被放弃的handler结束
+	close(timeoutRouter.Release)
+	<-timeoutRouter.Finished
+}
@@ -0,0 +1,75 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-acexy/starter-gin/ginstarter"
+	"github.com/golang-acexy/starter-gin/ginstarter/ginstartertest"
+)
+
+// 并发请求的TraceId互不影响 Span导出不阻塞请求
+func TestTracing(t *testing.T) {
+	release := make(chan struct{})
+	spans := make(chan *ginstarter.Span, 32)
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		Tracing: &ginstarter.TracingConfig{
+			ServiceName: "starter-gin-test",
+			Exporter: ginstarter.SpanExporterFunc(func(span *ginstarter.Span) {
+				<-release
+				spans <- span
+			}),
+		},
+		InitFunc: func(instance *gin.Engine) {
+			instance.GET("/trace", func(context *gin.Context) {
+				time.Sleep(10 * time.Millisecond)
+				context.String(http.StatusOK, ginstarter.TraceIdFromContext(context.Request.Context()))
+			})
+		},
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			traceId := fmt.Sprintf("%032x", i+1)
+			response := harness.Get("/trace").Header("traceparent", "00-"+traceId+"-00f067aa0ba902b7-01").
+				RemoteAddr("203.0.113.9:1234").Header("X-Forwarded-For", "127.0.0.1").Do()
+			if body := response.String(); body != traceId {
+				t.Errorf("request %d: expected trace id %s actual %s", i, traceId, body)
+			}
+			response.AssertHeader("Trace-Id", traceId)
+		}(i)
+	}
+	// 导出器阻塞时请求仍可完成
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("requests blocked by span exporter")
+	}
+	close(release)
+	for i := 0; i < 10; i++ {
+		select {
+		case span := <-spans:
+			if span.Name != "GET /trace" || span.ParentSpanId != "00f067aa0ba902b7" {
+				t.Errorf("unexpected span %s parent %s", span.Name, span.ParentSpanId)
+			}
+			// 未配置受信任代理时 使用连接的远端地址
+			if address := span.Attributes["client.address"]; address != "203.0.113.9" {
+				t.Errorf("unexpected client.address %v", address)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected 10 exported spans actual %d", i)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	// 不依赖系统时区数据库
+	_ "time/tzdata"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang-acexy/starter-gin/ginstarter"
+	"github.com/golang-acexy/starter-gin/ginstarter/ginstartertest"
+	"github.com/golang-acexy/starter-gin/test/router"
+)
+
+// 业务格式验证tag
+func TestValidatorTags(t *testing.T) {
+	// 构建引擎时注册验证tag
+	ginstartertest.New(t, ginstarter.GinConfig{})
+	v := binding.Validator.Engine().(*validator.Validate)
+
+	cases := []struct {
+		tag   string
+		value string
+		valid bool
+	}{
+		{"idcard", "11010519491231002X", true},
+		{"idcard", "11010519491231002x", true},
+		{"idcard", "310115198002290013", true},
+		{"idcard", "110105194912310021", false},  // 校验码错误
+		{"idcard", "440304199001011234", false},  // 校验码错误
+		{"idcard", "110105194902310026", false},  // 出生日期无效
+		{"idcard", "11010519491231002", false},   // 长度不足
+		{"idcard", "11010519491231002X0", false}, // 长度超出
+		{"idcard", "1101051949123100AX", false},  // 非数字字符
+		{"idcard", "", false},
+
+		{"uscc", "91350100M000100Y43", true},
+		{"uscc", "91110000600037341L", true},
+		{"uscc", "91350100m000100y43", true},
+		{"uscc", "91350100M000100Y44", false},  // 校验码错误
+		{"uscc", "91110000600037341M", false},  // 校验码错误
+		{"uscc", "91350100M000100Y4", false},   // 长度不足
+		{"uscc", "91350100M000100Y430", false}, // 长度超出
+		{"uscc", "91350100I000100Y43", false},  // 不允许的字符I
+		{"uscc", "91350100O000100Y43", false},  // 不允许的字符O
+		{"uscc", "91350100-000100Y43", false},
+
+		{"bankcard", "4111111111111111", true},
+		{"bankcard", "6011000990139424", true},
+		{"bankcard", "4111111111111112", false},     // 校验位错误
+		{"bankcard", "41111111116", false},          // 长度不足12位
+		{"bankcard", "41111111111111111113", false}, // 长度超出19位
+		{"bankcard", "4111 1111 1111 1111", false},  // 非数字字符
+		{"bankcard", "4111a11111111111", false},
+
+		{"mobile", "13800138000", true},
+		{"mobile", "19912345678", true},
+		{"mobile", "+8613800138000", true},
+		{"mobile", "8613800138000", true},
+		{"mobile", "12800138000", false},    // 号段无效
+		{"mobile", "1380013800", false},     // 长度不足
+		{"mobile", "138001380001", false},   // 长度超出
+		{"mobile", "1380013800a", false},    // 非数字字符
+		{"mobile", "+8513800138000", false}, // 国家代码错误
+
+		{"password", "Abcdef12", true},
+		{"password", "abcdef1!", true},
+		{"password", "ABCDEF1!", true},
+		{"password", "Abcdef1", false},                         // 长度不足
+		{"password", "Abc1!" + strings.Repeat("a", 60), false}, // 长度超出
+		{"password", "abcdefgh", false},                        // 字符种类不足
+		{"password", "abcdef12", false},
+		{"password", "Abc def12", false}, // 包含空白字符
+
+		{"cidrs", "10.0.0.0/8", true},
+		{"cidrs", "10.0.0.0/8, 192.168.0.0/16", true},
+		{"cidrs", "::1/128,fd00::/8", true},
+		{"cidrs", "", false},
+		{"cidrs", "10.0.0.1", false},    // 缺少前缀长度
+		{"cidrs", "10.0.0.0/33", false}, // 前缀长度超出
+		{"cidrs", "10.0.0.0/8,", false}, // 空项
+		{"cidrs", "10.0.0.256/8", false},
+		{"cidrs", "example.com/24", false},
+
+		{"filename", "report.pdf", true},
+		{"filename", "年度报告 2024.xlsx", true},
+		{"filename", ".gitignore", true},
+		{"filename", "", false},
+		{"filename", "..", false},
+		{"filename", "../etc/passwd", false}, // 路径分隔符
+		{"filename", `dir\file.txt`, false},
+		{"filename", "a\x00b.txt", false}, // 控制字符
+		{"filename", "file?.txt", false},
+		{"filename", "report.", false},  // 以.结尾
+		{"filename", " report", false},  // 首尾空白
+		{"filename", "CON", false},      // 系统保留名称
+		{"filename", "com1.txt", false}, // 保留名称不区分大小写及扩展名
+		{"filename", strings.Repeat("a", 256), false},
+
+		{"semver", "1.2.3", true},
+		{"semver", "0.0.1-alpha.1+build.5", true},
+		{"semver", "1.2", false},
+		{"semver", "v1.2.3", false},
+		{"semver", "01.2.3", false}, // 前导零
+		{"semver", "1.2.3-", false},
+
+		{"timezone", "UTC", true},
+		{"timezone", "Asia/Shanghai", true},
+		{"timezone", "America/New_York", true},
+		{"timezone", "Local", false},
+		{"timezone", "Asia/Nowhere", false},
+		{"timezone", "+08:00", false},
+
+		{"iso4217", "CNY", true},
+		{"iso4217", "USD", true},
+		{"iso4217", "cny", false}, // 需大写
+		{"iso4217", "XYZ", false},
+		{"iso4217", "US", false},
+	}
+	for _, c := range cases {
+		err := v.Var(c.value, c.tag)
+		if c.valid && err != nil {
+			t.Errorf("%s %q: expected valid got %v", c.tag, c.value, err)
+		} else if !c.valid && err == nil {
+			t.Errorf("%s %q: expected invalid", c.tag, c.value)
+		}
+	}
+}
+
+// 通过ValidatorConfig注册的验证及错误信息
+func TestValidatorConfig(t *testing.T) {
+	invalid := map[string]any{
+		"quantity": 3,
+		"sku":      "abc",
+		"price":    map[string]any{"cents": 0},
+		"range":    map[string]any{"min": 5, "max": 1},
+	}
+	valid := map[string]any{
+		"quantity": 2,
+		"sku":      "ABCD1234",
+		"price":    map[string]any{"cents": 100},
+		"range":    map[string]any{"min": 1, "max": 5},
+	}
+
+	// 验证器缓存结构体的字段名 先使用启用国际化的配置
+	harness := ginstartertest.New(t, ginstarter.GinConfig{
+		ValidatorConfig: router.OrderValidatorConfig(),
+		I18nConfig:      &ginstarter.I18nConfig{DefaultLocale: ginstarter.LocaleZh},
+		Routers:         []ginstarter.Router{&router.ValidatorRouter{}},
+	})
+	harness.Post("/validator/order").JSON(valid).Do().AssertSuccess()
+	status := harness.Post("/validator/order").JSON(invalid).Do().
+		AssertStatusCode(ginstarter.StatusCodeBadRequestParameters).Rest().Status
+	if expected := "quantity必须是偶数; sku必须是8位的商品编码; price必须大于0; max不能小于最小值"; string(status.StatusMessage) != expected {
+		t.Errorf("expected %q actual %q", expected, status.StatusMessage)
+	}
+
+	harness = ginstartertest.New(t, ginstarter.GinConfig{
+		ValidatorConfig: router.OrderValidatorConfig(),
+		Routers:         []ginstarter.Router{&router.ValidatorRouter{}},
+	})
+	harness.Post("/validator/order").JSON(valid).Do().AssertSuccess()
+	status = harness.Post("/validator/order").JSON(invalid).Do().
+		AssertStatusCode(ginstarter.StatusCodeBadRequestParameters).Rest().Status
+	if expected := "quantity must be even; sku must be an 8 character sku; price gt 0; max must not be less than min"; string(status.StatusMessage) != expected {
+		t.Errorf("expected %q actual %q", expected, status.StatusMessage)
+	}
+}